@@ -5,30 +5,113 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
 
 	"golang.org/x/oauth2"
 )
 
 const _configPath = "config.json"
 
+// _saveMu serializes config writes, since tokens may be persisted from background goroutines.
+var _saveMu sync.Mutex
+
 var C = &Config{
-	Telegram: &Telegram{},
-	LastFm:   &LastFm{},
-	Spotify:  &Spotify{},
+	Telegram:  &Telegram{},
+	LastFm:    &LastFm{},
+	Spotify:   &Spotify{},
+	MPD:       &MPD{},
+	Subsonic:  &Subsonic{},
+	Idle:      &Idle{},
+	Templates: &Templates{},
+	Time:      &Time{},
 }
 
 type (
 	LastFm struct {
 		APIKey   string `json:"apiKey"`
 		Username string `json:"username"`
+		// Tags and Similar toggle the line like "Tags: #indie · Similar: X, Y" in the post.
+		Tags    bool `json:"tags"`
+		Similar bool `json:"similar"`
 	}
 
 	Spotify struct {
-		Authorize    bool          `json:"authorize"`
-		RedirectURI  string        `json:"redirectURI"`
-		ClientID     string        `json:"clientID"`
-		ClientSecret string        `json:"clientSecret"`
-		Token        *oauth2.Token `json:"token"`
+		Authorize bool `json:"authorize"`
+		// AuthMode is how authorization is done. See SpotifyAuthMode* constants.
+		AuthMode     string `json:"authMode"`
+		RedirectURI  string `json:"redirectURI"`
+		ClientID     string `json:"clientID"`
+		ClientSecret string `json:"clientSecret"`
+		// Market is an ISO 3166-1 alpha-2 country code, or "auto" (or empty) for the profile country.
+		Market string `json:"market"`
+		// Playback toggles playback lines in the post.
+		Playback SpotifyPlayback `json:"playback"`
+		Token    *oauth2.Token   `json:"token"`
+	}
+
+	// SpotifyPlayback toggles parts of the playback line in the post.
+	// Device, Volume, Shuffle and Repeat require authorization with the user-read-playback-state scope.
+	SpotifyPlayback struct {
+		Context bool `json:"context"`
+		Device  bool `json:"device"`
+		Volume  bool `json:"volume"`
+		Shuffle bool `json:"shuffle"`
+		Repeat  bool `json:"repeat"`
+	}
+
+	MPD struct {
+		// Address is host:port, or a Unix socket path.
+		Address  string `json:"address"`
+		Password string `json:"password"`
+	}
+
+	Subsonic struct {
+		// URL of the server, like "https://music.example.com".
+		URL      string `json:"url"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		// Share links to a public share of the song. Sharing must be enabled on the server.
+		Share bool `json:"share"`
+		// ExposeCoverArt shows the cover. The cover URL contains an auth token,
+		// and it's visible to everyone who sees the post, so use a dedicated user.
+		ExposeCoverArt bool `json:"exposeCoverArt"`
+	}
+
+	// Idle is the post shown when nothing is playing.
+	Idle struct {
+		// Text is shown under the time. Can be empty.
+		Text string `json:"text"`
+		// Groups of links. Groups are separated by blank lines.
+		Groups []IdleGroup `json:"groups"`
+	}
+
+	IdleGroup struct {
+		Links []IdleLink `json:"links"`
+	}
+
+	IdleLink struct {
+		Emoji string `json:"emoji"`
+		Label string `json:"label"`
+		// URL can be empty, then the label is shown as text.
+		URL string `json:"url"`
+	}
+
+	// Templates are text/template posts. Empty means the default one.
+	// Inline template wins over the file.
+	Templates struct {
+		Playing     string `json:"playing"`
+		PlayingFile string `json:"playingFile"`
+		Idle        string `json:"idle"`
+		IdleFile    string `json:"idleFile"`
+	}
+
+	// Time of the posts.
+	Time struct {
+		// Zone is IANA time zone, like "Europe/Berlin", or "Local". Europe/Moscow by default.
+		Zone string `json:"zone"`
+		// Format is Go time layout, like "15:04 02.01.2006 (MST)". Depends on the locale by default.
+		Format string `json:"format"`
 	}
 
 	Telegram struct {
@@ -36,29 +119,130 @@ type (
 		UserID        int64  `json:"userID"`
 		ChatID        string `json:"chatID"`
 		ServiceChatID string `json:"serviceChatID"`
-		MessageID     int    `json:"messageID"`
+		// MessageID is the channel post. If 0, or the post is deleted, a new one is created.
+		MessageID int `json:"messageID"`
+		// Pin the created post.
+		Pin bool `json:"pin"`
+		// PostMode is the kind of the channel post. See PostMode* constants. Text by default.
+		PostMode string `json:"postMode"`
+		// PlaceholderPhoto is the photo URL (or file_id) of the post in photo mode,
+		// when nothing is playing or the item has no cover. Empty keeps the last photo.
+		PlaceholderPhoto string `json:"placeholderPhoto"`
+		// Keyboard shows the links as inline keyboard under the post.
+		Keyboard bool `json:"keyboard"`
+		// Targets are the posts to update. If set, the post fields above are ignored.
+		Targets []*Target `json:"targets"`
+	}
+
+	// Target is a post to update. Fields are the same as in Telegram.
+	Target struct {
+		ChatID           string `json:"chatID"`
+		MessageID        int    `json:"messageID"`
+		Pin              bool   `json:"pin"`
+		PostMode         string `json:"postMode"`
+		PlaceholderPhoto string `json:"placeholderPhoto"`
+		Keyboard         bool   `json:"keyboard"`
+		// Templates of the target. Empty ones are taken from the templates section.
+		Templates *Templates `json:"templates"`
+		// Playback toggles of the target. spotify.playback if not set.
+		Playback *SpotifyPlayback `json:"playback"`
 	}
 )
 
+// Now playing sources.
+const (
+	SourceSpotify = "spotify"
+	// SourceLastFm takes the "now playing" scrobble of LastFm.Username.
+	SourceLastFm = "lastfm"
+	// SourceMPD takes the current song of Music Player Daemon.
+	SourceMPD = "mpd"
+	// SourceSubsonic takes the now playing song of Subsonic.Username
+	// from a Subsonic-compatible server (Navidrome, Airsonic, Gonic, ...).
+	SourceSubsonic = "subsonic"
+)
+
+const (
+	// SpotifyAuthModeServer starts a temporary HTTP server on the redirect URI. Default.
+	SpotifyAuthModeServer = "server"
+
+	// SpotifyAuthModeTelegram sends the authorization URL to the owner via bot,
+	// and accepts the redirect URL back as a message.
+	SpotifyAuthModeTelegram = "telegram"
+)
+
+const (
+	// PostModeText is a text post with the cover as link preview. Default.
+	PostModeText = "text"
+
+	// PostModePhoto is a photo post with the cover as photo, and the text as caption.
+	// Caption is limited to 1024 characters, so long parts (like bio) may be dropped.
+	PostModePhoto = "photo"
+)
+
 type Config struct {
+	// Source is where the playing track is taken from. See Source* constants. Spotify by default.
+	Source string `json:"source"`
+	// Locale of the bot replies and posts: "en" (default) or "ru".
+	Locale   string    `json:"locale"`
 	Telegram *Telegram `json:"telegram"`
 	LastFm   *LastFm   `json:"lastFm"`
 	Spotify  *Spotify  `json:"spotify"`
+	MPD      *MPD      `json:"mpd"`
+	Subsonic *Subsonic `json:"subsonic"`
+	Idle     *Idle     `json:"idle"`
+	// Templates of the posts.
+	Templates *Templates `json:"templates"`
+	Time      *Time      `json:"time"`
 }
 
-// Save writes the config to the JSON file
+// Save writes the config to the JSON file.
+// The file is replaced atomically, so a crash mid-write never leaves a truncated config.
 func (c *Config) Save() error {
-	f, err := os.OpenFile(_configPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	_saveMu.Lock()
+	defer _saveMu.Unlock()
+	return c.save()
+}
+
+// Update applies fn to the config and saves it while holding the save lock,
+// so concurrent updates of different fields don't clobber each other.
+func (c *Config) Update(fn func(c *Config)) error {
+	_saveMu.Lock()
+	defer _saveMu.Unlock()
+	fn(c)
+	return c.save()
+}
+
+func (c *Config) save() error {
+	dir := filepath.Dir(_configPath)
+	f, err := os.CreateTemp(dir, filepath.Base(_configPath)+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("failed to open config file for saving: %w", err)
+		return fmt.Errorf("failed to create temp config file: %w", err)
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to chmod temp config file: %w", err)
 	}
-	defer f.Close()
 
 	enc := json.NewEncoder(f)
 	enc.SetIndent("", "\t")
 	if err := enc.Encode(c); err != nil {
+		f.Close()
 		return fmt.Errorf("failed to encode config to JSON: %w", err)
 	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync config file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close temp config file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, _configPath); err != nil {
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
 	return nil
 }
 
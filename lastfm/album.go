@@ -0,0 +1,37 @@
+package lastfm
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// AlbumGetInfo fetches detailed info for an album from Last.fm.
+// With username, the user playcount is included.
+// lang is an ISO639-2 code of the wiki.
+func (c *Client) AlbumGetInfo(ctx context.Context, artistName, albumName, username, lang string) (*AlbumInfo, error) {
+	if artistName == "" || albumName == "" {
+		return nil, errors.New("artist and album names are required")
+	}
+
+	query := url.Values{}
+	query.Set("artist", artistName)
+	query.Set("album", albumName)
+	if username != "" {
+		query.Set("username", username)
+	}
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+
+	var info AlbumInfo
+	if err := c.get(ctx, "album.getInfo", query, &info); err != nil {
+		if errors.Is(err, ErrInvalidParameters) {
+			// Album not found
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &info, nil
+}
@@ -1,64 +1,143 @@
 package lastfm
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"net/http"
+	"net/url"
+	"strconv"
 )
 
 // ArtistGetInfo fetches detailed info for an artist from Last.fm.
 // lang is an ISO639-2 code (see https://www.loc.gov/standards/iso639-2/php/code_list.php).
 func (c *Client) ArtistGetInfo(ctx context.Context, artistName, lang string) (*ArtistInfo, error) {
-	const method = "artist.getInfo"
-
 	if artistName == "" {
 		return nil, errors.New("artist name is required")
 	}
-	if c.APIKey == "" {
-		return nil, errors.New("API key is required")
-	}
 
-	apiURL := *_apiURL
-	query := apiURL.Query()
-
-	query.Set("method", method)
-	query.Set("api_key", c.APIKey)
-	query.Set("artist", artistName)
+	query := artistQuery(artistName)
 	if lang != "" {
 		query.Set("lang", lang)
 	}
-	query.Set("format", "json")
-	apiURL.RawQuery = query.Encode()
 
-	apiUrl := apiURL.String()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
-	if err != nil {
+	var info ArtistInfo
+	if err := c.get(ctx, "artist.getInfo", query, &info); err != nil {
+		if errors.Is(err, ErrInvalidParameters) {
+			// Artist not found
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	resp, err := c.HTTP.Do(req)
-	if err != nil {
+	return &info, nil
+}
+
+// ArtistGetSimilar fetches artists similar to the artist from Last.fm.
+// limit is optional, 0 for the default.
+func (c *Client) ArtistGetSimilar(ctx context.Context, artistName string, limit int) (*ArtistSimilar, error) {
+	if artistName == "" {
+		return nil, errors.New("artist name is required")
+	}
+
+	query := artistQuery(artistName)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var similar ArtistSimilar
+	if err := c.get(ctx, "artist.getSimilar", query, &similar); err != nil {
+		if errors.Is(err, ErrInvalidParameters) {
+			return nil, nil
+		}
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return &similar, nil
+}
+
+// ArtistGetTopTags fetches the top tags of the artist from Last.fm, ordered by popularity.
+func (c *Client) ArtistGetTopTags(ctx context.Context, artistName string) (*ArtistTopTags, error) {
+	if artistName == "" {
+		return nil, errors.New("artist name is required")
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		var apiErr ApiError
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			return nil, err
+	var tags ArtistTopTags
+	if err := c.get(ctx, "artist.getTopTags", artistQuery(artistName), &tags); err != nil {
+		if errors.Is(err, ErrInvalidParameters) {
+			return nil, nil
 		}
-		if apiErr.Code == 6 {
-			// Artist not found
+		return nil, err
+	}
+	return &tags, nil
+}
+
+// ArtistGetTopTracks fetches the top tracks of the artist from Last.fm.
+// limit is optional, 0 for the default.
+func (c *Client) ArtistGetTopTracks(ctx context.Context, artistName string, limit int) (*ArtistTopTracks, error) {
+	if artistName == "" {
+		return nil, errors.New("artist name is required")
+	}
+
+	query := artistQuery(artistName)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var tracks ArtistTopTracks
+	if err := c.get(ctx, "artist.getTopTracks", query, &tracks); err != nil {
+		if errors.Is(err, ErrInvalidParameters) {
 			return nil, nil
 		}
-		return nil, apiErr
+		return nil, err
 	}
+	return &tracks, nil
+}
 
-	var info ArtistInfo
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+// ArtistGetCorrection checks the artist name against the Last.fm corrections,
+// like "guns and roses" to "Guns N' Roses". Returns nil if there is no correction.
+func (c *Client) ArtistGetCorrection(ctx context.Context, artistName string) (*ArtistShort, error) {
+	if artistName == "" {
+		return nil, errors.New("artist name is required")
+	}
+
+	query := url.Values{}
+	query.Set("artist", artistName)
+
+	var resp struct {
+		// Corrections is a blank string without corrections.
+		Corrections json.RawMessage `json:"corrections"`
+	}
+	if err := c.get(ctx, "artist.getCorrection", query, &resp); err != nil {
+		if errors.Is(err, ErrInvalidParameters) {
+			// Unknown artist, nothing to correct.
+			return nil, nil
+		}
 		return nil, err
 	}
+	if !bytes.HasPrefix(bytes.TrimSpace(resp.Corrections), []byte("{")) {
+		return nil, nil
+	}
 
-	return &info, nil
+	var corrections struct {
+		Correction struct {
+			Artist *ArtistShort `json:"artist"`
+		} `json:"correction"`
+	}
+	if err := json.Unmarshal(resp.Corrections, &corrections); err != nil {
+		return nil, err
+	}
+	artist := corrections.Correction.Artist
+	if artist == nil || artist.Name == "" || artist.Name == artistName {
+		return nil, nil
+	}
+	return artist, nil
+}
+
+// artistQuery is the query of artist methods.
+// Misspelled names are corrected, so they are not "not found".
+func artistQuery(artistName string) url.Values {
+	query := url.Values{}
+	query.Set("artist", artistName)
+	query.Set("autocorrect", "1")
+	return query
 }
@@ -0,0 +1,77 @@
+package lastfm
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// Cache stores raw responses. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// DefaultCacheTTL are the TTLs of cached methods. Methods without TTL are not cached,
+// like user.getRecentTracks, which must be fresh. Requests with the username param
+// (track.getInfo and album.getInfo with the user playcount) are not cached either,
+// since playcounts change with every scrobble. user.* methods take the user param,
+// and are cached by TTL.
+var DefaultCacheTTL = map[string]time.Duration{
+	"artist.getInfo":       24 * time.Hour,
+	"artist.getSimilar":    24 * time.Hour,
+	"artist.getTopTags":    24 * time.Hour,
+	"artist.getTopTracks":  24 * time.Hour,
+	"artist.getCorrection": 24 * time.Hour,
+	"album.getInfo":        time.Hour,
+	"track.getInfo":        10 * time.Minute,
+	"user.getTopTracks":    time.Hour,
+	"user.getTopArtists":   time.Hour,
+}
+
+// DefaultCacheSize is the size of the default in-memory cache.
+const DefaultCacheSize = 256
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// memoryCache is an in-memory LRU cache with per-entry TTL.
+type memoryCache struct {
+	mu  sync.Mutex
+	lru *lru.Cache[string, memoryCacheEntry]
+}
+
+// NewMemoryCache creates an in-memory LRU cache of size entries.
+func NewMemoryCache(size int) Cache {
+	cache, _ := lru.New[string, memoryCacheEntry](max(size, 1))
+	return &memoryCache{lru: cache}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.lru.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *memoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Add(key, memoryCacheEntry{value: value, expires: time.Now().Add(ttl)})
+}
+
+// cacheKey is the method with sorted params.
+func cacheKey(method string, query url.Values) string {
+	return method + "?" + query.Encode()
+}
@@ -0,0 +1,95 @@
+package lastfm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	calls := 0
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Query().Get("method") {
+		case "user.getTopTracks":
+			w.Write([]byte(`{"toptracks":{"track":[]}}`))
+		case "track.getInfo":
+			w.Write([]byte(`{"track":{"name":"Song","userplaycount":"1"}}`))
+		default:
+			w.Write([]byte(`{"recenttracks":{"track":[]}}`))
+		}
+	})
+
+	ctx := context.Background()
+	for range 2 {
+		if _, err := cl.UserGetTopTracks(ctx, "user", nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cl.UserGetTopTracks(ctx, "other", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls of cached method, got %d", calls)
+	}
+
+	calls = 0
+	for range 2 {
+		if _, err := cl.UserGetRecentTracks(ctx, "user", nil, nil, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls of uncached method, got %d", calls)
+	}
+
+	calls = 0
+	for range 2 {
+		if _, err := cl.TrackGetInfo(ctx, "Artist", "Song", "user", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls with username, got %d", calls)
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	cache := NewMemoryCache(1)
+	cache.Set("a", []byte("1"), time.Hour)
+	cache.Set("b", []byte("2"), -time.Second)
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("expected evicted entry")
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected expired entry")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(50, 2)
+	start := time.Now()
+	for range 4 {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 of the burst, then 2 by 20ms.
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("limiter did not wait, elapsed %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Fatal("expected context error")
+	}
+
+	unlimited := NewLimiter(0, 0)
+	for range 100 {
+		if err := unlimited.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package lastfm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+// Documented Last.fm error codes. ApiError matches them with errors.Is.
+var (
+	// ErrInvalidParameters (6), like an artist which doesn't exist.
+	ErrInvalidParameters = errors.New("invalid parameters")
+	// ErrOperationFailed (8): most likely a backend error, try again.
+	ErrOperationFailed = errors.New("operation failed")
+	// ErrInvalidAPIKey (10).
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrServiceOffline (11): try again later.
+	ErrServiceOffline = errors.New("service offline")
+	// ErrServiceUnavailable (16): temporary error, try again.
+	ErrServiceUnavailable = errors.New("service temporarily unavailable")
+	// ErrSuspendedAPIKey (26).
+	ErrSuspendedAPIKey = errors.New("suspended API key")
+	// ErrRateLimitExceeded (29).
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+)
+
+var _codeErrors = map[int]error{
+	6:  ErrInvalidParameters,
+	8:  ErrOperationFailed,
+	10: ErrInvalidAPIKey,
+	11: ErrServiceOffline,
+	16: ErrServiceUnavailable,
+	26: ErrSuspendedAPIKey,
+	29: ErrRateLimitExceeded,
+}
+
+// Is reports whether the error has the code of target, like errors.Is(err, ErrInvalidAPIKey).
+func (e ApiError) Is(target error) bool {
+	codeErr, ok := _codeErrors[e.Code]
+	return ok && codeErr == target
+}
+
+// Temporary reports whether the request may succeed if repeated.
+func (e ApiError) Temporary() bool {
+	switch e.Code {
+	case 8, 11, 16, 29:
+		return true
+	}
+	return false
+}
+
+// Retry policy defaults.
+const (
+	DefaultMaxRetries = 2
+	DefaultRetryDelay = 500 * time.Millisecond
+)
+
+// maxRetryDelay is the highest upper bound of the backoff.
+const maxRetryDelay = time.Minute
+
+// isRetryable reports whether the request failed with a transient error.
+func isRetryable(err error) bool {
+	var apiErr ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryDelay returns the jittered exponential backoff of the attempt (from 0):
+// random in [base*2^attempt/2, base*2^attempt), the upper bound is at most maxRetryDelay.
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	// Compared before the shift, which overflows with big attempts.
+	d := maxRetryDelay
+	if attempt < 63 && base <= maxRetryDelay>>attempt {
+		d = base << attempt
+	}
+	if d < 2 {
+		return d
+	}
+	return d/2 + rand.N(d-d/2)
+}
+
+// sleep waits d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package lastfm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApiErrorIs(t *testing.T) {
+	err := error(ApiError{Code: 29, Message: "Rate Limit Exceeded"})
+	if !errors.Is(err, ErrRateLimitExceeded) || errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("unexpected match of %v", err)
+	}
+	if errors.Is(ApiError{Code: 999}, ErrOperationFailed) {
+		t.Fatal("unknown code matched")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Write([]byte(`{"error":16,"message":"Service temporarily unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"toptracks":{"track":[]}}`))
+	})
+	cl.RetryDelay = time.Millisecond
+	cl.Cache = nil
+
+	if _, err := cl.UserGetTopTracks(context.Background(), "user", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+
+	calls = 0
+	cl.MaxRetries = 1
+	_, err := cl.UserGetTopTracks(context.Background(), "user", nil, nil, nil)
+	if !errors.Is(err, ErrServiceUnavailable) || calls != 2 {
+		t.Fatalf("expected error after 2 calls, got %v after %d", err, calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := range 4 {
+		upper := 100 * time.Millisecond << attempt
+		for range 20 {
+			if d := retryDelay(100*time.Millisecond, attempt); d < upper/2 || d >= upper {
+				t.Fatalf("attempt %d: delay %s out of range", attempt, d)
+			}
+		}
+	}
+}
+
+func TestRetryDelayLargeAttempt(t *testing.T) {
+	for _, attempt := range []int{20, 40, 62, 63, 64, 1000} {
+		if d := retryDelay(time.Second, attempt); d < maxRetryDelay/2 || d >= maxRetryDelay {
+			t.Fatalf("attempt %d: delay %s out of range", attempt, d)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package lastfm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Default rate limit. Last.fm asks for well under 5 requests per second per key.
+const (
+	DefaultRateLimit = 4
+	DefaultRateBurst = 4
+)
+
+var _limiter = NewLimiter(DefaultRateLimit, DefaultRateBurst)
+
+// Limiter is a token bucket rate limiter, safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewLimiter creates a limiter of perSecond requests, with bursts of up to burst requests.
+// perSecond <= 0 means no limit.
+func NewLimiter(perSecond float64, burst int) *Limiter {
+	if perSecond <= 0 {
+		return &Limiter{}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a request is allowed, or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait <= 0 {
+			return nil
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token, or returns how long to wait for one.
+func (l *Limiter) reserve() time.Duration {
+	if l.interval <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) * float64(l.interval))
+}
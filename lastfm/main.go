@@ -1,28 +1,55 @@
 package lastfm
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
 )
 
-var _apiURL, _ = url.Parse("https://ws.audioscrobbler.com/2.0/")
+// DefaultBaseURL is the Last.fm API endpoint.
+const DefaultBaseURL = "https://ws.audioscrobbler.com/2.0/"
 
 // Client is a Last.fm API client.
 type Client struct {
 	APIKey string
-	HTTP   *http.Client
+	// BaseURL is the API endpoint. DefaultBaseURL by default.
+	BaseURL string
+	HTTP    *http.Client
+	// SignQuery is called with the complete query before every request,
+	// for example to add api_sig and sk of authenticated methods. Optional.
+	SignQuery func(query url.Values) error
+	// MaxRetries of transient errors (like rate limit, or service unavailable).
+	MaxRetries int
+	// RetryDelay is the base delay of the jittered exponential backoff.
+	RetryDelay time.Duration
+	// Limiter limits requests. Clients of NewClient share one limiter,
+	// since Last.fm limits requests by API key and IP. Optional.
+	Limiter *Limiter
+	// Cache of responses. Optional.
+	Cache Cache
+	// CacheTTL is the TTL of cached responses by method. Methods without TTL are not cached,
+	// requests with the username param (user playcount) are never cached.
+	CacheTTL map[string]time.Duration
 }
 
 // NewClient creates a new Last.fm API client.
 func NewClient(apiKey string) *Client {
 	return &Client{
-		APIKey: apiKey,
-		HTTP:   &http.Client{Timeout: 10 * time.Second},
+		APIKey:     apiKey,
+		BaseURL:    DefaultBaseURL,
+		HTTP:       &http.Client{Timeout: 10 * time.Second},
+		MaxRetries: DefaultMaxRetries,
+		RetryDelay: DefaultRetryDelay,
+		Limiter:    _limiter,
+		Cache:      NewMemoryCache(DefaultCacheSize),
+		CacheTTL:   DefaultCacheTTL,
 	}
 }
 
@@ -41,22 +68,13 @@ func NewClient(apiKey string) *Client {
 // to (Optional) : End timestamp of a range - only display scrobbles before this time, in UNIX timestamp format (integer number of seconds since 00:00:00, January 1st 1970 UTC). This must be in the UTC time zone.
 //
 // api_key (Required) : A Last.fm API key.
-func (c *Client) UserGetRecentTracks(user string, limit *int, page *int, from *time.Time, extended *bool, to *time.Time) (*UserGetRecentTracksResponse, error) {
-	const method = "user.getRecentTracks"
-
+func (c *Client) UserGetRecentTracks(ctx context.Context, user string, limit *int, page *int, from *time.Time, extended *bool, to *time.Time) (*UserGetRecentTracksResponse, error) {
 	if user == "" {
 		return nil, errors.New("user is required")
 	}
-	if c.APIKey == "" {
-		return nil, errors.New("API key is required")
-	}
 
-	apiURL := *_apiURL
-	query := apiURL.Query()
-	query.Set("method", method)
+	query := url.Values{}
 	query.Set("user", user)
-	query.Set("api_key", c.APIKey)
-
 	if limit != nil {
 		query.Set("limit", strconv.Itoa(*limit))
 	}
@@ -73,29 +91,146 @@ func (c *Client) UserGetRecentTracks(user string, limit *int, page *int, from *t
 		query.Set("to", fmt.Sprintf("%d", to.UTC().Unix()))
 	}
 
+	respDec := &UserGetRecentTracksResponse{}
+	if err := c.get(ctx, "user.getRecentTracks", query, respDec); err != nil {
+		return nil, err
+	}
+	return respDec, nil
+}
+
+// get calls the API method and decodes the response into result.
+// Responses are cached by method TTL, transient errors are retried.
+func (c *Client) get(ctx context.Context, method string, query url.Values, result any) error {
+	if c.APIKey == "" {
+		return errors.New("API key is required")
+	}
+
+	ttl := c.CacheTTL[method]
+	if query.Has("username") {
+		// User playcount of track.getInfo and others.
+		ttl = 0
+	}
+	key := cacheKey(method, query)
+	if c.Cache != nil && ttl > 0 {
+		if body, ok := c.Cache.Get(key); ok {
+			return json.Unmarshal(body, result)
+		}
+	}
+
+	var (
+		body []byte
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		body, err = c.do(ctx, method, query)
+		if err == nil || attempt >= c.MaxRetries || !isRetryable(err) {
+			break
+		}
+		if err := sleep(ctx, retryDelay(c.RetryDelay, attempt)); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return err
+	}
+	if c.Cache != nil && ttl > 0 {
+		c.Cache.Set(key, body, ttl)
+	}
+	return nil
+}
+
+// do calls the API method once, and returns the body.
+// Last.fm may return errors with HTTP 200, so the body is always checked for the error.
+func (c *Client) do(ctx context.Context, method string, query url.Values) ([]byte, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	apiURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("base URL: %w", err)
+	}
+
+	// Copy, since the query is signed for every attempt.
+	params := url.Values{}
+	for k, v := range query {
+		params[k] = v
+	}
+	query = params
+	query.Set("method", method)
+	query.Set("api_key", c.APIKey)
 	query.Set("format", "json")
+	if c.SignQuery != nil {
+		if err := c.SignQuery(query); err != nil {
+			return nil, fmt.Errorf("sign query: %w", err)
+		}
+	}
 	apiURL.RawQuery = query.Encode()
 
-	resp, err := c.HTTP.Get(apiURL.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		var apiErr ApiError
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			return nil, err
-		}
-		return nil, apiErr
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	respDec := &UserGetRecentTracksResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(respDec); err != nil {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
 		return nil, err
 	}
 
-	return respDec, nil
+	if apiErr, ok := decodeApiError(body); ok {
+		return nil, apiErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpStatusError{code: resp.StatusCode, status: resp.Status}
+	}
+	return body, nil
+}
+
+// httpStatusError is an unexpected HTTP status without the API error.
+type httpStatusError struct {
+	code   int
+	status string
+}
+
+func (e httpStatusError) Error() string {
+	return "unexpected HTTP status: " + e.status
+}
+
+// decodeApiError decodes the body like {"error": 6, "message": "..."}.
+func decodeApiError(body []byte) (ApiError, bool) {
+	body = bytes.TrimSpace(body)
+	if !bytes.HasPrefix(body, []byte("{")) {
+		return ApiError{}, false
+	}
+	var apiErr struct {
+		Message string          `json:"message"`
+		Code    json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err != nil || len(apiErr.Code) == 0 {
+		return ApiError{}, false
+	}
+	code, err := strconv.Atoi(string(apiErr.Code))
+	if err != nil {
+		// Not the error object, like "error" field of some entity.
+		return ApiError{}, false
+	}
+	return ApiError{Message: apiErr.Message, Code: code}, true
 }
 
 // btoi converts a bool to int (true=1, false=0).
@@ -1,23 +1,198 @@
 package lastfm
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
-func getClient() *Client {
-	return NewClient("")
+func getClient(t *testing.T, handler http.HandlerFunc) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cl := NewClient("key")
+	cl.BaseURL = srv.URL + "/2.0/"
+	// Shared by all clients, tested separately.
+	cl.Limiter = nil
+	return cl
 }
 
 func TestUserGetRecentTracks(t *testing.T) {
-	tracks, err := getClient().UserGetRecentTracks("", tp(1), nil, nil, tp(true), nil)
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("method") != "user.getRecentTracks" || query.Get("api_key") != "key" ||
+			query.Get("user") != "user" || query.Get("limit") != "1" || query.Get("extended") != "1" || query.Get("sig") != "signed" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"recenttracks":{"track":[{"name":"Song","artist":{"name":"Artist"},"@attr":{"nowplaying":"true"}}]}}`))
+	})
+	cl.SignQuery = func(query url.Values) error {
+		query.Set("sig", "signed")
+		return nil
+	}
+
+	tracks, err := cl.UserGetRecentTracks(context.Background(), "user", tp(1), nil, nil, tp(true), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if tracks != nil {
-		if len(tracks.Recenttracks.Track) > 0 {
-			tr := tracks.Recenttracks.Track[0]
-			t.Logf("artist %s, track: %s, mbid: %s", tr.Artist.Name, tr.Name, tr.Mbid)
+	if len(tracks.Recenttracks.Track) != 1 {
+		t.Fatalf("unexpected tracks %+v", tracks)
+	}
+	if tr := tracks.Recenttracks.Track[0]; tr.Name != "Song" || tr.Artist.Name != "Artist" {
+		t.Fatalf("unexpected track %+v", tr)
+	}
+}
+
+func TestApiError(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusForbidden} {
+		cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":10,"message":"Invalid API key"}`))
+		})
+
+		_, err := cl.UserGetTopTracks(context.Background(), "user", nil, nil, nil)
+		var apiErr ApiError
+		if !errors.As(err, &apiErr) || apiErr.Code != 10 {
+			t.Fatalf("status %d: expected API error, got %v", status, err)
+		}
+	}
+}
+
+func TestArtistGetInfoNotFound(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":6,"message":"The artist you supplied could not be found"}`))
+	})
+
+	info, err := cl.ArtistGetInfo(context.Background(), "Nobody", "")
+	if err != nil || info != nil {
+		t.Fatalf("expected nothing, got %v, %v", info, err)
+	}
+}
+
+func TestTrackGetInfo(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("method") != "track.getInfo" || query.Get("username") != "user" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
 		}
+		w.Write([]byte(`{"track":{"name":"Song","duration":"240000","listeners":"100","playcount":"1000",` +
+			`"artist":{"name":"Artist"},"userplaycount":42,"userloved":"1",` +
+			`"toptags":{"tag":{"name":"shoegaze","url":"https://www.last.fm/tag/shoegaze"}},` +
+			`"wiki":{"summary":"About the song."}}}`))
+	})
+
+	info, err := cl.TrackGetInfo(context.Background(), "Artist", "Song", "user", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := info.Track
+	if track.Duration != 240000 || track.Listeners != 100 || track.UserPlaycount != 42 || !bool(track.UserLoved) {
+		t.Fatalf("unexpected track %+v", track)
+	}
+	if len(track.TopTags.Tag) != 1 || track.TopTags.Tag[0].Name != "shoegaze" || track.Wiki == nil || track.Album != nil {
+		t.Fatalf("unexpected track %+v", track)
+	}
+}
+
+func TestAlbumGetInfo(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"album":{"name":"Album","artist":"Artist","playcount":"5","userplaycount":"3","tags":"",` +
+			`"tracks":{"track":{"name":"Song","duration":null,"@attr":{"rank":1}}}}}`))
+	})
+
+	info, err := cl.AlbumGetInfo(context.Background(), "Artist", "Album", "user", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	album := info.Album
+	if album.Playcount != 5 || album.UserPlaycount != 3 || len(album.Tags.Tag) != 0 || album.Wiki != nil {
+		t.Fatalf("unexpected album %+v", album)
+	}
+	if len(album.Tracks.Track) != 1 || album.Tracks.Track[0].Name != "Song" || album.Tracks.Track[0].Attr.Rank != 1 {
+		t.Fatalf("unexpected tracks %+v", album.Tracks)
+	}
+}
+
+func TestAlbumGetInfoNoTracks(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"album":{"name":"Album","artist":"Artist","userplaycount":"3","tracks":"",` +
+			`"wiki":{"summary":"Album story."}}}`))
+	})
+
+	info, err := cl.AlbumGetInfo(context.Background(), "Artist", "Album", "user", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	album := info.Album
+	if album.UserPlaycount != 3 || len(album.Tracks.Track) != 0 || album.Wiki == nil || album.Wiki.Summary != "Album story." {
+		t.Fatalf("unexpected album %+v", album)
+	}
+}
+
+func TestArtistGetSimilar(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("method") != "artist.getSimilar" || query.Get("autocorrect") != "1" || query.Get("limit") != "2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"similarartists":{"artist":[{"name":"Slowdive","match":"1"},{"name":"Ride","match":"0.8"}],"@attr":{"artist":"My Bloody Valentine"}}}`))
+	})
+
+	similar, err := cl.ArtistGetSimilar(context.Background(), "my bloody valentine", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artists := similar.SimilarArtists.Artist; len(artists) != 2 || artists[1].Name != "Ride" {
+		t.Fatalf("unexpected similar %+v", similar)
+	}
+}
+
+func TestArtistGetTopTracks(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("method") != "artist.getTopTracks" || query.Get("autocorrect") != "1" || query.Get("limit") != "1" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"toptracks":{"track":[{"name":"Only Shallow","playcount":"100","@attr":{"rank":"1"}}]}}`))
+	})
+
+	tracks, err := cl.ArtistGetTopTracks(context.Background(), "my bloody valentine", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := tracks.TopTracks.Track; len(list) != 1 || list[0].Name != "Only Shallow" || list[0].Attr.Rank != 1 {
+		t.Fatalf("unexpected top tracks %+v", tracks)
+	}
+}
+
+func TestArtistGetCorrection(t *testing.T) {
+	responses := map[string]string{
+		"guns and roses": `{"corrections":{"correction":{"artist":{"name":"Guns N' Roses","url":"https://www.last.fm/music/Guns+N%27+Roses"},"@attr":{"index":"0"}}}}`,
+		"Guns N' Roses":  `{"corrections":"\n"}`,
+	}
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responses[r.URL.Query().Get("artist")]))
+	})
+
+	artist, err := cl.ArtistGetCorrection(context.Background(), "guns and roses")
+	if err != nil || artist == nil || artist.Name != "Guns N' Roses" {
+		t.Fatalf("unexpected correction %+v, %v", artist, err)
+	}
+	artist, err = cl.ArtistGetCorrection(context.Background(), "Guns N' Roses")
+	if err != nil || artist != nil {
+		t.Fatalf("expected no correction, got %+v, %v", artist, err)
+	}
+}
+
+func TestArtistGetCorrectionUnknown(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":6,"message":"The artist you supplied could not be found"}`))
+	})
+	artist, err := cl.ArtistGetCorrection(context.Background(), "nope")
+	if err != nil || artist != nil {
+		t.Fatalf("expected no correction, got %+v, %v", artist, err)
 	}
 }
 
@@ -1,9 +1,11 @@
 package lastfm
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -210,6 +212,218 @@ type ArtistInfo struct {
 	} `json:"artist"`
 }
 
+// ArtistSimilar represents artists similar to the artist.
+type ArtistSimilar struct {
+	SimilarArtists struct {
+		Artist []struct {
+			Name  string  `json:"name"`
+			Mbid  string  `json:"mbid"`
+			URL   string  `json:"url"`
+			Image []Image `json:"image"`
+			// Match is the similarity from 0 to 1, like "0.75".
+			Match string `json:"match"`
+		} `json:"artist"`
+		Attr struct {
+			Artist string `json:"artist"`
+		} `json:"@attr"`
+	} `json:"similarartists"`
+}
+
+// ArtistTopTags represents the top tags of the artist.
+type ArtistTopTags struct {
+	TopTags Tags `json:"toptags"`
+}
+
+// ArtistTopTracks represents the top tracks of the artist.
+type ArtistTopTracks struct {
+	TopTracks struct {
+		Track []struct {
+			Name      string      `json:"name"`
+			Mbid      string      `json:"mbid"`
+			URL       string      `json:"url"`
+			Image     []Image     `json:"image"`
+			Artist    ArtistShort `json:"artist"`
+			Listeners Int         `json:"listeners"`
+			Playcount Int         `json:"playcount"`
+			Attr      struct {
+				Rank Int `json:"rank"`
+			} `json:"@attr"`
+		} `json:"track"`
+	} `json:"toptracks"`
+}
+
+// TrackInfo represents detailed information about a track.
+type TrackInfo struct {
+	Track struct {
+		Name string `json:"name"`
+		Mbid string `json:"mbid"`
+		URL  string `json:"url"`
+		// Duration in milliseconds. Can be 0.
+		Duration  Int         `json:"duration"`
+		Listeners Int         `json:"listeners"`
+		Playcount Int         `json:"playcount"`
+		Artist    ArtistShort `json:"artist"`
+		// Album can be nil.
+		Album *struct {
+			Artist string  `json:"artist"`
+			Title  string  `json:"title"`
+			Mbid   string  `json:"mbid"`
+			URL    string  `json:"url"`
+			Image  []Image `json:"image"`
+		} `json:"album"`
+		// UserPlaycount and UserLoved are known if requested with username.
+		UserPlaycount Int  `json:"userplaycount"`
+		UserLoved     Bool `json:"userloved"`
+		TopTags       Tags `json:"toptags"`
+		// Wiki can be nil.
+		Wiki *Wiki `json:"wiki"`
+	} `json:"track"`
+}
+
+// AlbumInfo represents detailed information about an album.
+type AlbumInfo struct {
+	Album struct {
+		Name      string  `json:"name"`
+		Artist    string  `json:"artist"`
+		Mbid      string  `json:"mbid"`
+		URL       string  `json:"url"`
+		Image     []Image `json:"image"`
+		Listeners Int     `json:"listeners"`
+		Playcount Int     `json:"playcount"`
+		// UserPlaycount is known if requested with username.
+		UserPlaycount Int         `json:"userplaycount"`
+		Tags          Tags        `json:"tags"`
+		Tracks        AlbumTracks `json:"tracks"`
+		// Wiki can be nil.
+		Wiki *Wiki `json:"wiki"`
+	} `json:"album"`
+}
+
+// AlbumTrack represents a track of the album tracklist.
+type AlbumTrack struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Duration in seconds. Can be 0.
+	Duration Int         `json:"duration"`
+	Artist   ArtistShort `json:"artist"`
+	Attr     struct {
+		Rank Int `json:"rank"`
+	} `json:"@attr"`
+}
+
+// AlbumTracks is the album tracklist.
+type AlbumTracks struct {
+	Track []AlbumTrack `json:"track"`
+}
+
+// UnmarshalJSON decodes the tracklist, where no tracks is a string,
+// and a single track is sent as an object.
+func (t *AlbumTracks) UnmarshalJSON(data []byte) error {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		t.Track = nil
+		return nil
+	}
+	var aux struct {
+		Track json.RawMessage `json:"track"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	tracks, err := oneOrMany[AlbumTrack](aux.Track)
+	if err != nil {
+		return err
+	}
+	t.Track = tracks
+	return nil
+}
+
+// Tag represents a tag of an artist, album or track.
+type Tag struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Count is the weight of the tag (0-100). Top tags only.
+	Count Int `json:"count"`
+}
+
+// Tags is a list of tags.
+type Tags struct {
+	Tag []Tag `json:"tag"`
+}
+
+// UnmarshalJSON decodes tags, where no tags is an empty string,
+// and a single tag is sent as an object.
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		t.Tag = nil
+		return nil
+	}
+	var aux struct {
+		Tag json.RawMessage `json:"tag"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	tags, err := oneOrMany[Tag](aux.Tag)
+	if err != nil {
+		return err
+	}
+	t.Tag = tags
+	return nil
+}
+
+// Wiki represents a wiki of an album or track.
+type Wiki struct {
+	Published string `json:"published"`
+	Summary   string `json:"summary"`
+	Content   string `json:"content"`
+}
+
+// Int is a number, which Last.fm sends as a string or a number.
+type Int int64
+
+// UnmarshalJSON decodes numbers like 42, "42", or "" (as 0).
+func (n *Int) UnmarshalJSON(data []byte) error {
+	str := strings.Trim(string(data), `"`)
+	if str == "" || str == "null" {
+		*n = 0
+		return nil
+	}
+	val, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("number %s: %w", data, err)
+	}
+	*n = Int(val)
+	return nil
+}
+
+// Bool is a boolean, which Last.fm sends as "0" or "1".
+type Bool bool
+
+// UnmarshalJSON decodes booleans like "1", 1, or true.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	str := strings.Trim(string(data), `"`)
+	*b = Bool(str == "1" || strings.EqualFold(str, "true"))
+	return nil
+}
+
+// oneOrMany decodes an array, or a single object as an array of one.
+func oneOrMany[T any](data []byte) ([]T, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+	if data[0] == '[' {
+		var many []T
+		err := json.Unmarshal(data, &many)
+		return many, err
+	}
+	var one T
+	if err := json.Unmarshal(data, &one); err != nil {
+		return nil, err
+	}
+	return []T{one}, nil
+}
+
 // Validate checks if the Artist struct has required fields.
 func (a *Artist) Validate() error {
 	if a.Name == "" {
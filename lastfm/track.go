@@ -0,0 +1,37 @@
+package lastfm
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// TrackGetInfo fetches detailed info for a track from Last.fm.
+// With username, the user playcount and loved status are included.
+// lang is an ISO639-2 code of the wiki.
+func (c *Client) TrackGetInfo(ctx context.Context, artistName, trackName, username, lang string) (*TrackInfo, error) {
+	if artistName == "" || trackName == "" {
+		return nil, errors.New("artist and track names are required")
+	}
+
+	query := url.Values{}
+	query.Set("artist", artistName)
+	query.Set("track", trackName)
+	if username != "" {
+		query.Set("username", username)
+	}
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+
+	var info TrackInfo
+	if err := c.get(ctx, "track.getInfo", query, &info); err != nil {
+		if errors.Is(err, ErrInvalidParameters) {
+			// Track not found
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &info, nil
+}
@@ -1,9 +1,9 @@
 package lastfm
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"net/http"
+	"net/url"
 	"strconv"
 )
 
@@ -20,22 +20,13 @@ const (
 )
 
 // UserGetTopTracks fetches top tracks for a user from Last.fm.
-func (c *Client) UserGetTopTracks(user string, period *UserGetTopTracksPeriod, limit *int, page *int) (*UserGetTopTracksResponse, error) {
-	const method = "user.getTopTracks"
-
+func (c *Client) UserGetTopTracks(ctx context.Context, user string, period *UserGetTopTracksPeriod, limit *int, page *int) (*UserGetTopTracksResponse, error) {
 	if user == "" {
 		return nil, errors.New("user is required")
 	}
-	if c.APIKey == "" {
-		return nil, errors.New("API key is required")
-	}
 
-	apiURL := *_apiURL
-	query := apiURL.Query()
-	query.Set("method", method)
+	query := url.Values{}
 	query.Set("user", user)
-	query.Set("api_key", c.APIKey)
-
 	if limit != nil {
 		query.Set("limit", strconv.Itoa(*limit))
 	}
@@ -46,48 +37,21 @@ func (c *Client) UserGetTopTracks(user string, period *UserGetTopTracksPeriod, l
 		query.Set("period", string(*period))
 	}
 
-	query.Set("format", "json")
-	apiURL.RawQuery = query.Encode()
-
-	resp, err := c.HTTP.Get(apiURL.String())
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var apiErr ApiError
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			return nil, err
-		}
-		return nil, apiErr
-	}
-
 	respDec := &UserGetTopTracksResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(respDec); err != nil {
+	if err := c.get(ctx, "user.getTopTracks", query, respDec); err != nil {
 		return nil, err
 	}
-
 	return respDec, nil
 }
 
 // UserGetTopArtists fetches top artists for a user from Last.fm.
-func (c *Client) UserGetTopArtists(user string, period *UserGetTopTracksPeriod, limit *int, page *int) (*UserGetTopArtistsResponse, error) {
-	const method = "user.getTopArtists"
-
+func (c *Client) UserGetTopArtists(ctx context.Context, user string, period *UserGetTopTracksPeriod, limit *int, page *int) (*UserGetTopArtistsResponse, error) {
 	if user == "" {
 		return nil, errors.New("user is required")
 	}
-	if c.APIKey == "" {
-		return nil, errors.New("API key is required")
-	}
 
-	apiURL := *_apiURL
-	query := apiURL.Query()
-	query.Set("method", method)
+	query := url.Values{}
 	query.Set("user", user)
-	query.Set("api_key", c.APIKey)
-
 	if limit != nil {
 		query.Set("limit", strconv.Itoa(*limit))
 	}
@@ -98,27 +62,9 @@ func (c *Client) UserGetTopArtists(user string, period *UserGetTopTracksPeriod,
 		query.Set("period", string(*period))
 	}
 
-	query.Set("format", "json")
-	apiURL.RawQuery = query.Encode()
-
-	resp, err := c.HTTP.Get(apiURL.String())
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		var apiErr ApiError
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			return nil, err
-		}
-		return nil, apiErr
-	}
-
 	respDec := &UserGetTopArtistsResponse{}
-	if err := json.NewDecoder(resp.Body).Decode(respDec); err != nil {
+	if err := c.get(ctx, "user.getTopArtists", query, respDec); err != nil {
 		return nil, err
 	}
-
 	return respDec, nil
 }
@@ -0,0 +1,253 @@
+package locale
+
+const (
+	BotInit     Key = "bot.init"
+	BotAllGood  Key = "bot.allGood"
+	BotStopped  Key = "bot.stopped"
+	BotError    Key = "bot.error"
+	BotNotReady Key = "bot.notReady"
+
+	BotUnknownCommand Key = "bot.unknownCommand"
+	BotAlreadyAsking  Key = "bot.alreadyAsking"
+
+	CmdHelp    Key = "cmd.help"
+	CmdStop    Key = "cmd.stop"
+	CmdNow     Key = "cmd.now"
+	CmdStatus  Key = "cmd.status"
+	CmdPause   Key = "cmd.pause"
+	CmdResume  Key = "cmd.resume"
+	CmdIdle    Key = "cmd.idle"
+	CmdRefresh Key = "cmd.refresh"
+
+	CmdModules     Key = "cmd.modules"
+	CmdStartModule Key = "cmd.startModule"
+	CmdStopModule  Key = "cmd.stopModule"
+	CmdRestart     Key = "cmd.restart"
+
+	ModuleNone         Key = "module.none"
+	ModuleNotFound     Key = "module.notFound"
+	ModuleUsage        Key = "module.usage"
+	ModuleStarted      Key = "module.started"
+	ModuleRunningMsg   Key = "module.running"
+	ModuleStoppedMsg   Key = "module.stopped"
+	ModuleNotRunning   Key = "module.notRunning"
+	ModuleRestarted    Key = "module.restarted"
+	ModuleRestartedAll Key = "module.restartedAll"
+	ModuleStateRunning Key = "module.stateRunning"
+	ModuleStateStopped Key = "module.stateStopped"
+
+	NowNothing     Key = "now.nothing"
+	NowPaused      Key = "now.paused"
+	PlayerPaused   Key = "player.paused"
+	PlayerResumed  Key = "player.resumed"
+	PlayerIdle     Key = "player.idle"
+	PlayerRefresh  Key = "player.refresh"
+	PlayerBusy     Key = "player.busy"
+	StatusUptime   Key = "status.uptime"
+	StatusRunning  Key = "status.running"
+	StatusPaused   Key = "status.paused"
+	StatusStopped  Key = "status.stopped"
+	StatusLastPoll Key = "status.lastPoll"
+	StatusNever    Key = "status.never"
+	StatusLastErr  Key = "status.lastErr"
+	StatusNoErr    Key = "status.noErr"
+	StatusCache    Key = "status.cache"
+
+	AuthPrompt      Key = "auth.prompt"
+	AuthRetry       Key = "auth.retry"
+	AuthTokenFailed Key = "auth.tokenFailed"
+	AuthComplete    Key = "auth.complete"
+
+	AuthRefreshFailed Key = "auth.refreshFailed"
+	AuthEmptyAnswer   Key = "auth.emptyAnswer"
+	AuthInvalidURL    Key = "auth.invalidURL"
+	AuthNoCode        Key = "auth.noCode"
+	AuthDenied        Key = "auth.denied"
+	AuthStateMismatch Key = "auth.stateMismatch"
+	AuthCodeUsed      Key = "auth.codeUsed"
+
+	PlaybackFrom Key = "playback.from"
+	PlaybackOn   Key = "playback.on"
+
+	ContextAlbum      Key = "context.album"
+	ContextArtist     Key = "context.artist"
+	ContextPlaylist   Key = "context.playlist"
+	ContextShow       Key = "context.show"
+	ContextCollection Key = "context.collection"
+
+	LastFmPlays   Key = "lastfm.plays"
+	LastFmTags    Key = "lastfm.tags"
+	LastFmSimilar Key = "lastfm.similar"
+
+	PoweredBy Key = "poweredBy"
+)
+
+var _timeLayouts = map[string]string{
+	EN: "3:04:05 PM, Jan 2, 2006 (MST)",
+	RU: "15:04:05 02.01.2006 (MST)",
+}
+
+var _catalogs = map[string]map[Key]string{
+	EN: {
+		BotInit:     "Telegram user ID: %d\nService chat ID: %d",
+		BotAllGood:  "All good.",
+		BotStopped:  "Bot stopped.",
+		BotError:    "Error: %s",
+		BotNotReady: "telegram userID and serviceChatID must be set",
+
+		BotUnknownCommand: "Unknown command. See /help.",
+		BotAlreadyAsking:  "Already waiting for an answer.",
+
+		CmdHelp:    "List of commands",
+		CmdStop:    "Stop the bot",
+		CmdNow:     "What is playing now",
+		CmdStatus:  "Monitoring status",
+		CmdPause:   "Pause post updates",
+		CmdResume:  "Resume post updates",
+		CmdIdle:    "Show the idle post and pause",
+		CmdRefresh: "Update the post right now",
+
+		CmdModules:     "Modules and their state",
+		CmdStartModule: "Start module: /startmodule <name>",
+		CmdStopModule:  "Stop module: /stopmodule <name>",
+		CmdRestart:     "Restart module, or all: /restart [name]",
+
+		ModuleNone:         "No modules.",
+		ModuleNotFound:     "unknown module %q, see /modules",
+		ModuleUsage:        "Usage: /%s <module>. See /modules.",
+		ModuleStarted:      "Module %s started.",
+		ModuleRunningMsg:   "Module %s is already running.",
+		ModuleStoppedMsg:   "Module %s stopped.",
+		ModuleNotRunning:   "Module %s is not running, use /startmodule %[1]s.",
+		ModuleRestarted:    "Module %s restarted.",
+		ModuleRestartedAll: "All modules restarted.",
+		ModuleStateRunning: "running",
+		ModuleStateStopped: "stopped",
+
+		NowNothing:     "Nothing is playing.",
+		NowPaused:      "(paused)",
+		PlayerPaused:   "Post updates paused. /resume to continue.",
+		PlayerResumed:  "Post updates resumed.",
+		PlayerIdle:     "Idle post shown, updates paused. /resume to continue.",
+		PlayerRefresh:  "Updating the post.",
+		PlayerBusy:     "Busy, try again later.",
+		StatusUptime:   "Uptime: %s",
+		StatusRunning:  "Updates: running",
+		StatusPaused:   "Updates: paused",
+		StatusStopped:  "Updates: stopped",
+		StatusLastPoll: "Last poll: %s",
+		StatusNever:    "never",
+		StatusLastErr:  "Last error: %s, %s",
+		StatusNoErr:    "Last error: none",
+		StatusCache:    "Cache: %d artists, %d tracks",
+
+		AuthPrompt:      "Open this URL and authorize Spotify:\n\n%s\n\nThen send me the URL you were redirected to.",
+		AuthRetry:       "%s. Try again.",
+		AuthTokenFailed: "Failed to get token: %s. Try again.",
+		AuthComplete:    "Spotify authorization complete.",
+
+		AuthRefreshFailed: `Spotify token refresh failed, set "authorize": true in config and restart`,
+		AuthEmptyAnswer:   "Empty answer",
+		AuthInvalidURL:    "Invalid redirect URL",
+		AuthNoCode:        "No code in the redirect URL",
+		AuthDenied:        "Authorization denied: %s",
+		AuthStateMismatch: "The URL is from another authorization attempt",
+		AuthCodeUsed:      "The code is already used",
+
+		PlaybackFrom: "from %s",
+		PlaybackOn:   "on %s",
+
+		ContextAlbum:      "album",
+		ContextArtist:     "artist",
+		ContextPlaylist:   "playlist",
+		ContextShow:       "show",
+		ContextCollection: "Liked Songs",
+
+		LastFmPlays:   "🎧 Your plays: %d",
+		LastFmTags:    "Tags: %s",
+		LastFmSimilar: "Similar: %s",
+
+		PoweredBy: "powered by oklookat/teletrack",
+	},
+	RU: {
+		BotInit:     "ID пользователя Telegram: %d\nID служебного чата: %d",
+		BotAllGood:  "Всё хорошо.",
+		BotStopped:  "Бот остановлен.",
+		BotError:    "Ошибка: %s",
+		BotNotReady: "нужно указать telegram userID и serviceChatID",
+
+		BotUnknownCommand: "Неизвестная команда. Смотрите /help.",
+		BotAlreadyAsking:  "Уже жду ответа.",
+
+		CmdHelp:    "Список команд",
+		CmdStop:    "Остановить бота",
+		CmdNow:     "Что сейчас играет",
+		CmdStatus:  "Состояние мониторинга",
+		CmdPause:   "Приостановить обновление поста",
+		CmdResume:  "Возобновить обновление поста",
+		CmdIdle:    "Показать пост простоя и приостановить",
+		CmdRefresh: "Обновить пост прямо сейчас",
+
+		CmdModules:     "Модули и их состояние",
+		CmdStartModule: "Запустить модуль: /startmodule <имя>",
+		CmdStopModule:  "Остановить модуль: /stopmodule <имя>",
+		CmdRestart:     "Перезапустить модуль или все: /restart [имя]",
+
+		ModuleNone:         "Модулей нет.",
+		ModuleNotFound:     "неизвестный модуль %q, смотрите /modules",
+		ModuleUsage:        "Использование: /%s <модуль>. Смотрите /modules.",
+		ModuleStarted:      "Модуль %s запущен.",
+		ModuleRunningMsg:   "Модуль %s уже запущен.",
+		ModuleStoppedMsg:   "Модуль %s остановлен.",
+		ModuleNotRunning:   "Модуль %s не запущен, используйте /startmodule %[1]s.",
+		ModuleRestarted:    "Модуль %s перезапущен.",
+		ModuleRestartedAll: "Все модули перезапущены.",
+		ModuleStateRunning: "работает",
+		ModuleStateStopped: "остановлен",
+
+		NowNothing:     "Сейчас ничего не играет.",
+		NowPaused:      "(пауза)",
+		PlayerPaused:   "Обновление поста приостановлено. /resume, чтобы продолжить.",
+		PlayerResumed:  "Обновление поста возобновлено.",
+		PlayerIdle:     "Показан пост простоя, обновление приостановлено. /resume, чтобы продолжить.",
+		PlayerRefresh:  "Обновляю пост.",
+		PlayerBusy:     "Занят, попробуйте позже.",
+		StatusUptime:   "Время работы: %s",
+		StatusRunning:  "Обновление: работает",
+		StatusPaused:   "Обновление: приостановлено",
+		StatusStopped:  "Обновление: остановлено",
+		StatusLastPoll: "Последний опрос: %s",
+		StatusNever:    "никогда",
+		StatusLastErr:  "Последняя ошибка: %s, %s",
+		StatusNoErr:    "Последняя ошибка: нет",
+		StatusCache:    "Кэш: исполнителей %d, треков %d",
+
+		AuthPrompt:      "Откройте ссылку и авторизуйте Spotify:\n\n%s\n\nЗатем пришлите мне ссылку, на которую вас перенаправило.",
+		AuthRetry:       "%s. Попробуйте ещё раз.",
+		AuthTokenFailed: "Не удалось получить токен: %s. Попробуйте ещё раз.",
+		AuthComplete:    "Авторизация Spotify завершена.",
+
+		AuthRefreshFailed: `Не удалось обновить токен Spotify, укажите "authorize": true в конфиге и перезапустите`,
+		AuthEmptyAnswer:   "Пустой ответ",
+		AuthInvalidURL:    "Неверная ссылка",
+		AuthNoCode:        "В ссылке нет кода",
+		AuthDenied:        "Авторизация отклонена: %s",
+		AuthStateMismatch: "Ссылка от другой попытки авторизации",
+		AuthCodeUsed:      "Код уже использован",
+
+		PlaybackFrom: "из %s",
+		PlaybackOn:   "на %s",
+
+		ContextAlbum:      "альбома",
+		ContextArtist:     "исполнителя",
+		ContextPlaylist:   "плейлиста",
+		ContextShow:       "подкаста",
+		ContextCollection: "Любимых треков",
+
+		LastFmPlays:   "🎧 Ваших прослушиваний: %d",
+		LastFmTags:    "Теги: %s",
+		LastFmSimilar: "Похожие: %s",
+
+		PoweredBy: "работает на oklookat/teletrack",
+	},
+}
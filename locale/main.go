@@ -0,0 +1,95 @@
+// Package locale has the message catalogs and locale-aware formatting.
+package locale
+
+import (
+	"fmt"
+	"time"
+	// Minimal containers may have no zoneinfo.
+	_ "time/tzdata"
+)
+
+const (
+	EN = "en"
+	RU = "ru"
+)
+
+// Key is a message key in the catalogs.
+type Key string
+
+// DefaultTimeZone is used when the time zone is not set.
+const DefaultTimeZone = "Europe/Moscow"
+
+var (
+	_current    = EN
+	_location   = mustLoadLocation(DefaultTimeZone)
+	_timeLayout string
+)
+
+// Set sets the current locale. Empty means English.
+func Set(lang string) error {
+	if lang == "" {
+		lang = EN
+	}
+	if _, ok := _catalogs[lang]; !ok {
+		return fmt.Errorf("unknown locale %q", lang)
+	}
+	_current = lang
+	return nil
+}
+
+// Current returns the current locale.
+func Current() string {
+	return _current
+}
+
+// T returns the message in the current locale, formatted with args.
+// Falls back to English, then to the key itself.
+func T(key Key, args ...any) string {
+	msg, ok := _catalogs[_current][key]
+	if !ok {
+		msg, ok = _catalogs[EN][key]
+	}
+	if !ok {
+		msg = string(key)
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// SetTime sets the IANA time zone (like "Europe/Berlin", or "Local") and the Go time layout of FormatTime.
+// Empty zone means DefaultTimeZone, empty layout means the layout of the current locale.
+func SetTime(zone, layout string) error {
+	if zone == "" {
+		zone = DefaultTimeZone
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return fmt.Errorf("time zone: %w", err)
+	}
+	_location = loc
+	_timeLayout = layout
+	return nil
+}
+
+// FormatTime formats time in the configured time zone, with the configured layout,
+// or the layout of the current locale.
+func FormatTime(t time.Time) string {
+	layout := _timeLayout
+	if layout == "" {
+		var ok bool
+		if layout, ok = _timeLayouts[_current]; !ok {
+			layout = _timeLayouts[EN]
+		}
+	}
+	return t.In(_location).Format(layout)
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
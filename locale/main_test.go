@@ -0,0 +1,59 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestT(t *testing.T) {
+	t.Cleanup(func() { Set(EN) })
+
+	if err := Set("xx"); err == nil {
+		t.Fatal("expected error for unknown locale")
+	}
+	if err := Set(RU); err != nil {
+		t.Fatal(err)
+	}
+	if got := T(BotError, "x"); got != "Ошибка: x" {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if got := T("missing"); got != "missing" {
+		t.Fatalf("expected key, got %q", got)
+	}
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for lang, catalog := range _catalogs {
+		for key := range _catalogs[EN] {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("%s: missing %s", lang, key)
+			}
+		}
+		if _, ok := _timeLayouts[lang]; !ok {
+			t.Errorf("%s: missing time layout", lang)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	t.Cleanup(func() {
+		Set(EN)
+		SetTime("", "")
+	})
+
+	tm := time.Date(2025, 2, 1, 9, 34, 56, 0, time.UTC)
+	Set(RU)
+	if got := FormatTime(tm); got != "12:34:56 01.02.2025 (MSK)" {
+		t.Fatalf("unexpected time %q", got)
+	}
+
+	if err := SetTime("Europe/Berlin", "15:04 MST"); err != nil {
+		t.Fatal(err)
+	}
+	if got := FormatTime(tm); got != "10:34 CET" {
+		t.Fatalf("unexpected time %q", got)
+	}
+	if err := SetTime("Mars/Olympus", ""); err == nil {
+		t.Fatal("expected error for unknown zone")
+	}
+}
@@ -3,16 +3,22 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/lastfm"
+	"github.com/oklookat/teletrack/locale"
 	"github.com/oklookat/teletrack/module/spotify"
 
 	"github.com/oklookat/teletrack/spoty"
 	"github.com/oklookat/teletrack/telegram"
+	spotifyapi "github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
 )
 
 func main() {
@@ -29,9 +35,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := locale.Set(config.C.Locale); err != nil {
+		slog.Error("failed to set locale", "err", err)
+		os.Exit(1)
+	}
+	if err := locale.SetTime(config.C.Time.Zone, config.C.Time.Format); err != nil {
+		slog.Error("failed to set time", "err", err)
+		os.Exit(1)
+	}
+
+	// Fail fast, before any post is sent.
+	targets, err := spotify.LoadTargets(config.C)
+	if err != nil {
+		slog.Error("failed to load targets", "err", err)
+		os.Exit(1)
+	}
+
+	useSpotify := config.C.Source == "" || config.C.Source == config.SourceSpotify
+	telegramAuth := config.C.Spotify.AuthMode == config.SpotifyAuthModeTelegram
+
 	// Spotify authorization
-	if config.C.Spotify.Authorize {
-		if err := authorizeSpotify(ctx); err != nil {
+	if useSpotify && config.C.Spotify.Authorize && !telegramAuth {
+		if err := authorizeSpotify(ctx, nil); err != nil {
 			slog.Error("spotify authorization failed", "err", err)
 			os.Exit(1)
 		}
@@ -39,48 +64,138 @@ func main() {
 		return
 	}
 
-	spotifyCl := spoty.GetClient(
-		config.C.Spotify.RedirectURI,
-		config.C.Spotify.ClientID,
-		config.C.Spotify.ClientSecret,
-		config.C.Spotify.Token,
-	)
-
 	// Initialize Telegram bot
-	var tgBot *telegram.TelegramBot
-	tgBot, err := telegram.NewTelegramBot(ctx, config.C.Telegram, []telegram.Module{
-		spotify.NewPlayer(spotifyCl, func(err error) error {
-			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-				return err
-			}
-			tgBot.SendError(ctx, err)
-			return nil
-		}),
-	})
+	tgBot, err := telegram.NewTelegramBot(ctx, config.C.Telegram, nil)
 	if err != nil {
 		slog.Error("failed to start telegram bot", "err", err)
 		os.Exit(1)
 	}
 
+	// Spotify authorization via bot. Monitoring starts right after it.
+	if useSpotify && config.C.Spotify.Authorize && telegramAuth {
+		authCtx, authCancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-tgBot.StopChannel():
+				authCancel()
+			case <-authCtx.Done():
+			}
+		}()
+		err := authorizeSpotify(authCtx, tgBot)
+		authCancel()
+		if errors.Is(err, context.Canceled) {
+			// Stopped by /stop or a signal, not a failure.
+			slog.Info("spotify authorization canceled, shutting down application")
+			tgBot.Shutdown()
+			return
+		}
+		if err != nil {
+			slog.Error("spotify authorization failed", "err", err)
+			os.Exit(1)
+		}
+		slog.Info("Spotify authorization complete")
+		tgBot.Notify(ctx, locale.T(locale.AuthComplete))
+	}
+
+	source, err := newSource(ctx, tgBot)
+	if err != nil {
+		slog.Error("failed to create source", "err", err)
+		os.Exit(1)
+	}
+
+	player := spotify.NewPlayer(source, targets, func(err error) error {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		tgBot.SendError(ctx, err)
+		return nil
+	})
+	if err := tgBot.AddModule(player); err != nil {
+		slog.Error("failed to add module", "err", err)
+		os.Exit(1)
+	}
+
+	// SIGHUP restarts the modules.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
 	// Wait until context is canceled or /stop is received
-	select {
-	case <-ctx.Done():
-	case <-tgBot.StopChannel():
-		slog.Info("stop signal received")
+	for running := true; running; {
+		select {
+		case <-ctx.Done():
+			running = false
+		case <-tgBot.StopChannel():
+			slog.Info("stop signal received")
+			running = false
+		case <-hup:
+			slog.Info("restarting modules")
+			if err := tgBot.RestartModule(""); err != nil {
+				slog.Error("failed to restart modules", "err", err)
+			}
+		}
 	}
 
 	slog.Info("shutting down application")
+	tgBot.Shutdown()
 }
 
-// authorizeSpotify runs OAuth and saves the token
-func authorizeSpotify(ctx context.Context) error {
-	token, err := spoty.Authorize(ctx, config.C.Spotify, func(url string) {
-		slog.Info("Go to URL for Spotify auth", "url", url)
-	})
+// newSource creates the now playing source selected in config.
+func newSource(ctx context.Context, tgBot *telegram.TelegramBot) (spotify.NowPlayingSource, error) {
+	switch config.C.Source {
+	case "", config.SourceSpotify:
+		return spotify.NewSpotifySource(newSpotifyClient(ctx, tgBot), config.C.Spotify), nil
+	case config.SourceLastFm:
+		return spotify.NewLastFmSource(lastfm.NewClient(config.C.LastFm.APIKey), config.C.LastFm.Username), nil
+	case config.SourceMPD:
+		return spotify.NewMpdSource(config.C.MPD.Address, config.C.MPD.Password), nil
+	case config.SourceSubsonic:
+		return spotify.NewSubsonicSource(config.C.Subsonic), nil
+	}
+	return nil, fmt.Errorf("unknown source %q", config.C.Source)
+}
+
+// newSpotifyClient creates a Spotify client, which saves refreshed tokens to config.
+func newSpotifyClient(ctx context.Context, tgBot *telegram.TelegramBot) *spotifyapi.Client {
+	return spoty.GetClient(
+		ctx,
+		config.C.Spotify.RedirectURI,
+		config.C.Spotify.ClientID,
+		config.C.Spotify.ClientSecret,
+		config.C.Spotify.Token,
+		spoty.TokenHooks{
+			OnToken: func(token *oauth2.Token) error {
+				return config.C.Update(func(c *config.Config) {
+					c.Spotify.Token = token
+				})
+			},
+			OnRefreshFailed: func(err error) {
+				slog.Error("spotify token refresh failed", "err", err)
+				tgBot.SendError(ctx, fmt.Errorf("%s: %w", locale.T(locale.AuthRefreshFailed), err))
+			},
+		},
+	)
+}
+
+// authorizeSpotify runs OAuth and saves the token.
+// If tgBot is not nil, authorization is done through the bot instead of a local HTTP server.
+func authorizeSpotify(ctx context.Context, tgBot *telegram.TelegramBot) error {
+	var (
+		token *oauth2.Token
+		err   error
+	)
+	if tgBot != nil {
+		token, err = spoty.AuthorizeManual(ctx, config.C.Spotify, tgBot.Ask)
+	} else {
+		token, err = spoty.Authorize(ctx, config.C.Spotify, func(url string) {
+			slog.Info("Go to URL for Spotify auth", "url", url)
+		})
+	}
 	if err != nil {
 		return err
 	}
-	config.C.Spotify.Authorize = false
-	config.C.Spotify.Token = token
-	return config.C.Save()
+	return config.C.Update(func(c *config.Config) {
+		c.Spotify.Authorize = false
+		c.Spotify.Token = token
+	})
 }
@@ -2,28 +2,58 @@ package spotify
 
 import (
 	"context"
+	"errors"
 
+	"github.com/oklookat/teletrack/config"
 	"github.com/oklookat/teletrack/lastfm"
-	"github.com/oklookat/teletrack/spoty"
 )
 
-func (s *spotifyPlayerHookImpl) fetchArtistInfo(ctx context.Context, track *spoty.CurrentPlaying) *cachedArtistInfo {
+func (s *spotifyPlayerHookImpl) fetchArtistInfo(ctx context.Context, track *NowPlaying) *cachedArtistInfo {
+	if track.Type == NowPlayingEpisode {
+		// Last.fm knows nothing about podcasts.
+		return &cachedArtistInfo{}
+	}
+	if len(track.Artist) == 0 {
+		// Streams and untagged files.
+		return &cachedArtistInfo{}
+	}
+
 	if cached, ok := s.cachedArtists.Get(track.ArtistID); ok {
 		return &cached
 	}
 
+	cached := cachedArtistInfo{Name: track.Artist}
+	corrected, err := s.fetchArtistCorrection(ctx, track.Artist)
+	if err != nil {
+		s.reportError("fetch artist correction", err)
+		if isLastFmKeyError(err) {
+			// Other requests fail the same way.
+			s.cachedArtists.Add(track.ArtistID, cached)
+			return &cached
+		}
+	} else if len(corrected) > 0 {
+		cached.Name = corrected
+	}
+
 	ctxTimeout, cancel := context.WithTimeout(ctx, artistInfoFetchTimeout)
 	defer cancel()
 
-	var gotInfo *lastfm.ArtistInfo
+	var (
+		gotInfo   *lastfm.ArtistInfo
+		keyFailed bool
+	)
 
 	langs := []string{"en", "ru"}
 	for _, lang := range langs {
-		info, err := s.lastFmClient.ArtistGetInfo(ctxTimeout, track.Artist, lang)
+		info, err := s.lastFmClient.ArtistGetInfo(ctxTimeout, cached.Name, lang)
 		if err != nil {
 			if s.onError != nil {
 				s.onError(wrapErr("fetch artist info", err))
 			}
+			if keyFailed = isLastFmKeyError(err); keyFailed {
+				// Other languages fail the same way.
+				break
+			}
 			continue
 		}
 		if info != nil {
@@ -32,9 +62,119 @@ func (s *spotifyPlayerHookImpl) fetchArtistInfo(ctx context.Context, track *spot
 		}
 	}
 
-	cached := cachedArtistInfo{}
 	cached.format(gotInfo)
+	if !keyFailed {
+		s.fetchArtistTags(ctx, cached.Name, &cached)
+	}
 
 	s.cachedArtists.Add(track.ArtistID, cached)
 	return &cached
 }
+
+// fetchArtistCorrection returns the artist name as spelled on Last.fm, or "" if it's spelled right.
+func (s *spotifyPlayerHookImpl) fetchArtistCorrection(ctx context.Context, artist string) (string, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, artistInfoFetchTimeout)
+	defer cancel()
+
+	corrected, err := s.lastFmClient.ArtistGetCorrection(ctxTimeout, artist)
+	if err != nil || corrected == nil {
+		return "", err
+	}
+	return corrected.Name, nil
+}
+
+// fetchArtistTags sets the top tags and similar artists, if enabled.
+// Every request has its own timeout, the artist info requests may have used up theirs.
+func (s *spotifyPlayerHookImpl) fetchArtistTags(ctx context.Context, artist string, cached *cachedArtistInfo) {
+	if config.C.LastFm.Tags {
+		ctxTimeout, cancel := context.WithTimeout(ctx, artistInfoFetchTimeout)
+		tags, err := s.lastFmClient.ArtistGetTopTags(ctxTimeout, artist)
+		cancel()
+		if err != nil {
+			s.reportError("fetch artist tags", err)
+		} else if tags != nil {
+			for _, tag := range tags.TopTags.Tag {
+				if len(cached.Tags) == maxArtistTags {
+					break
+				}
+				cached.Tags = append(cached.Tags, tag.Name)
+			}
+		}
+	}
+
+	if config.C.LastFm.Similar {
+		ctxTimeout, cancel := context.WithTimeout(ctx, artistInfoFetchTimeout)
+		similar, err := s.lastFmClient.ArtistGetSimilar(ctxTimeout, artist, maxSimilarArtists)
+		cancel()
+		if err != nil {
+			s.reportError("fetch similar artists", err)
+		} else if similar != nil {
+			for _, ar := range similar.SimilarArtists.Artist {
+				if len(cached.Similar) == maxSimilarArtists {
+					break
+				}
+				cached.Similar = append(cached.Similar, LinkData{Label: ar.Name, URL: ar.URL})
+			}
+		}
+	}
+}
+
+// fetchLastFmTrackInfo sets the user playcount and the album blurb.
+func (s *spotifyPlayerHookImpl) fetchLastFmTrackInfo(ctx context.Context, track *NowPlaying, cached *cachedTrackInfo) {
+	if len(cached.Artist) == 0 {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, trackInfoFetchTimeout)
+	defer cancel()
+
+	if err := s.fetchUserPlays(ctxTimeout, track, cached); isLastFmKeyError(err) {
+		return
+	}
+
+	if len(track.Album) > 0 {
+		info, err := s.lastFmClient.AlbumGetInfo(ctxTimeout, cached.Artist, track.Album, "", "")
+		if err != nil {
+			s.reportError("fetch album info", err)
+		} else if info != nil && info.Album.Wiki != nil {
+			cached.AlbumBlurb = plainText(info.Album.Wiki.Summary, maxAlbumBlurbLength)
+		}
+	}
+}
+
+// refreshPlays updates the user playcount of the cached track, the rest of it stays.
+func (s *spotifyPlayerHookImpl) refreshPlays(ctx context.Context, track *NowPlaying) {
+	cached, ok := s.cachedTracks.Peek(track.ID)
+	if !ok || track.Type == NowPlayingEpisode || len(cached.Artist) == 0 {
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, trackInfoFetchTimeout)
+	defer cancel()
+
+	if err := s.fetchUserPlays(ctxTimeout, track, &cached); err == nil {
+		s.cachedTracks.Add(track.ID, cached)
+	}
+}
+
+// fetchUserPlays sets the user playcount, if the username is set.
+func (s *spotifyPlayerHookImpl) fetchUserPlays(ctx context.Context, track *NowPlaying, cached *cachedTrackInfo) error {
+	username := config.C.LastFm.Username
+	if len(username) == 0 {
+		return nil
+	}
+	info, err := s.lastFmClient.TrackGetInfo(ctx, cached.Artist, track.Name, username, "")
+	if err != nil {
+		s.reportError("fetch track info", err)
+		return err
+	}
+	if info != nil {
+		cached.Plays = int(info.Track.UserPlaycount)
+	}
+	return nil
+}
+
+// isLastFmKeyError is true if the API key is wrong, so other requests fail the same way.
+func isLastFmKeyError(err error) bool {
+	return errors.Is(err, lastfm.ErrInvalidAPIKey) || errors.Is(err, lastfm.ErrSuspendedAPIKey)
+}
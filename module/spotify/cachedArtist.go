@@ -1,29 +1,33 @@
 package spotify
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/oklookat/teletrack/lastfm"
-	"github.com/oklookat/teletrack/shared"
 	"github.com/oklookat/teletrack/shared/lastfmclean"
 )
 
 const (
 	artistInfoFetchTimeout = 5 * time.Second
+	maxArtistTags          = 3
+	maxSimilarArtists      = 3
 )
 
 type cachedArtistInfo struct {
-	// TG-formatted
-	Bio        string
-	LastFmLink string
+	// Name is the artist name as spelled on Last.fm, like "Guns N' Roses" for "guns and roses".
+	Name      string
+	Bio       string
+	LastFmURL string
+	// Tags and Similar are fetched if enabled in config.
+	Tags    []string
+	Similar []LinkData
 }
 
 func (a *cachedArtistInfo) format(info *lastfm.ArtistInfo) {
 	if info != nil {
 		a.setBio(info)
 		if len(info.Artist.URL) > 0 {
-			a.LastFmLink = fmt.Sprintf("🔗 %s", shared.TgLink("Last.fm", info.Artist.URL))
+			a.LastFmURL = info.Artist.URL
 		}
 	}
 }
@@ -42,6 +46,5 @@ func (a *cachedArtistInfo) setBio(info *lastfm.ArtistInfo) {
 		RemoveMarkdown:   true,
 	})
 
-	bio := cleaner.Clean(info.Artist.Bio.Summary)
-	a.Bio = shared.TgText(bio)
+	a.Bio = cleaner.Clean(info.Artist.Bio.Summary)
 }
@@ -2,21 +2,42 @@ package spotify
 
 import (
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/oklookat/teletrack/shared"
-	"github.com/oklookat/teletrack/spoty"
 )
 
-func (s *spotifyPlayerHookImpl) fetchTrackInfo(ctx context.Context, track *spoty.CurrentPlaying) *cachedTrackInfo {
+const (
+	trackInfoFetchTimeout = 5 * time.Second
+	maxDescriptionLength  = 300
+	maxAlbumBlurbLength   = 300
+)
+
+// fetchTrackInfo gets the track info. artist is the artist name of the lookups,
+// as corrected by Last.fm.
+func (s *spotifyPlayerHookImpl) fetchTrackInfo(ctx context.Context, track *NowPlaying, artist string) *cachedTrackInfo {
 	if cached, ok := s.cachedTracks.Get(track.ID); ok {
 		return &cached
 	}
 
+	fullName := track.Name
+	if len(artist) > 0 {
+		fullName = artist + " - " + track.Name
+	}
+
 	cached := cachedTrackInfo{
-		TrackName:   fmt.Sprintf("`%s`", shared.SanitizeCodeSpan(track.Artist+" - "+track.Name)),
-		SpotifyLink: fmt.Sprintf("🔗 %s", shared.TgLink("Spotify", "https://open.spotify.com/track/"+track.ID)),
-		Emoji:       shared.TgText(shared.TotalRandomEmoji()),
+		FullName: fullName,
+		Artist:   artist,
+		Link:     track.Link,
+		Emoji:    shared.TotalRandomEmoji(),
+	}
+
+	if track.Type == NowPlayingEpisode {
+		cached.FullName = track.ShowName + " - " + track.Name
+		cached.Publisher = track.Publisher
+		cached.Description = plainText(track.Description, maxDescriptionLength)
+	} else {
+		s.fetchLastFmTrackInfo(ctx, track, &cached)
 	}
 
 	s.cachedTracks.Add(track.ID, cached)
@@ -24,7 +45,19 @@ func (s *spotifyPlayerHookImpl) fetchTrackInfo(ctx context.Context, track *spoty
 }
 
 type cachedTrackInfo struct {
-	TrackName   string
-	SpotifyLink string
-	Emoji       string
+	FullName string
+	// Artist is the artist name of the Last.fm lookups. Can be empty.
+	Artist string
+	// Link to the item on the source. Can be empty.
+	Link  string
+	Emoji string
+
+	// Track only, from Last.fm.
+	// Plays is the user playcount, 0 if unknown.
+	Plays      int
+	AlbumBlurb string
+
+	// Episode only.
+	Publisher   string
+	Description string
 }
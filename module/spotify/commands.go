@@ -0,0 +1,162 @@
+package spotify
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/oklookat/teletrack/locale"
+	"github.com/oklookat/teletrack/telegram"
+)
+
+type playerControl int
+
+const (
+	// controlRefresh updates the post right now.
+	controlRefresh playerControl = iota
+	// controlIdle shows the idle post.
+	controlIdle
+)
+
+// Commands returns the bot commands of the player.
+func (p *Player) Commands() []telegram.Command {
+	return []telegram.Command{
+		{Name: "now", Description: locale.T(locale.CmdNow), Handler: p.cmdNow},
+		{Name: "status", Description: locale.T(locale.CmdStatus), Handler: p.cmdStatus},
+		{Name: "pause", Description: locale.T(locale.CmdPause), Handler: p.cmdPause},
+		{Name: "resume", Description: locale.T(locale.CmdResume), Handler: p.cmdResume},
+		{Name: "idle", Description: locale.T(locale.CmdIdle), Handler: p.cmdIdle},
+		{Name: "refresh", Description: locale.T(locale.CmdRefresh), Handler: p.cmdRefresh},
+	}
+}
+
+func (p *Player) cmdNow(ctx context.Context, args string) string {
+	p.statusMu.Lock()
+	current := p.current
+	p.statusMu.Unlock()
+	if current == nil {
+		return locale.T(locale.NowNothing)
+	}
+
+	name := current.Name
+	switch {
+	case current.Type == NowPlayingEpisode && len(current.ShowName) > 0:
+		name = current.ShowName + " - " + name
+	case len(current.Artist) > 0:
+		name = current.Artist + " - " + name
+	}
+	lines := []string{"🎵 " + name}
+	if current.DurationMs > 0 {
+		lines = append(lines, formatTime(current.ProgressMs)+" / "+formatTime(current.DurationMs))
+	}
+	if !current.Playing {
+		lines = append(lines, locale.T(locale.NowPaused))
+	}
+	if len(current.Link) > 0 {
+		lines = append(lines, current.Link)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *Player) cmdStatus(ctx context.Context, args string) string {
+	// Before statusMu, Start locks them in the other order.
+	running := p.Status() == telegram.ModuleRunning
+
+	p.statusMu.Lock()
+	defer p.statusMu.Unlock()
+
+	lines := []string{
+		locale.T(locale.StatusUptime, time.Since(p.startedAt).Round(time.Second)),
+	}
+	switch {
+	case !running:
+		lines = append(lines, locale.T(locale.StatusStopped))
+	case p.paused.Load():
+		lines = append(lines, locale.T(locale.StatusPaused))
+	default:
+		lines = append(lines, locale.T(locale.StatusRunning))
+	}
+	if p.lastPoll.IsZero() {
+		lines = append(lines, locale.T(locale.StatusLastPoll, locale.T(locale.StatusNever)))
+	} else {
+		lines = append(lines, locale.T(locale.StatusLastPoll, locale.FormatTime(p.lastPoll)))
+	}
+	if p.lastErr == nil {
+		lines = append(lines, locale.T(locale.StatusNoErr))
+	} else {
+		lines = append(lines, locale.T(locale.StatusLastErr, locale.FormatTime(p.lastErrTime), p.lastErr))
+	}
+	if hooks, ok := p.hooks.(*spotifyPlayerHookImpl); ok {
+		lines = append(lines, locale.T(locale.StatusCache, hooks.cachedArtists.Len(), hooks.cachedTracks.Len()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *Player) cmdPause(ctx context.Context, args string) string {
+	if p.Status() != telegram.ModuleRunning {
+		return locale.T(locale.ModuleNotRunning, p.Name())
+	}
+	p.paused.Store(true)
+	return locale.T(locale.PlayerPaused)
+}
+
+func (p *Player) cmdResume(ctx context.Context, args string) string {
+	if p.Status() != telegram.ModuleRunning {
+		return locale.T(locale.ModuleNotRunning, p.Name())
+	}
+	p.paused.Store(false)
+	return locale.T(locale.PlayerResumed)
+}
+
+func (p *Player) cmdIdle(ctx context.Context, args string) string {
+	if p.Status() != telegram.ModuleRunning {
+		return locale.T(locale.ModuleNotRunning, p.Name())
+	}
+	// Otherwise the next tick replaces the idle post.
+	p.paused.Store(true)
+	if !p.sendControl(controlIdle) {
+		return locale.T(locale.PlayerBusy)
+	}
+	return locale.T(locale.PlayerIdle)
+}
+
+func (p *Player) cmdRefresh(ctx context.Context, args string) string {
+	if p.Status() != telegram.ModuleRunning {
+		return locale.T(locale.ModuleNotRunning, p.Name())
+	}
+	if !p.sendControl(controlRefresh) {
+		return locale.T(locale.PlayerBusy)
+	}
+	return locale.T(locale.PlayerRefresh)
+}
+
+func (p *Player) sendControl(ctl playerControl) bool {
+	select {
+	case p.control <- ctl:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleControl runs the command in the monitor loop, so hooks are never called concurrently.
+// The lock is released before posting, so commands are not blocked by the network.
+func (p *Player) handleControl(ctx context.Context, b *bot.Bot, ctl playerControl) {
+	if p.hooks == nil {
+		return
+	}
+	switch ctl {
+	case controlRefresh:
+		p.Lock()
+		p.lastPlayed = nil
+		p.hooks.Reset()
+		p.Unlock()
+		p.tick(ctx, b, true)
+	case controlIdle:
+		p.Lock()
+		p.lastPlayed = nil
+		p.Unlock()
+		p.hooks.OnNothingPlaying(ctx, b)
+	}
+}
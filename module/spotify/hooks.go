@@ -4,13 +4,13 @@ import (
 	"context"
 	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/oklookat/teletrack/config"
 	"github.com/oklookat/teletrack/lastfm"
-	"github.com/oklookat/teletrack/spoty"
 )
 
 type BotSender interface {
@@ -19,25 +19,27 @@ type BotSender interface {
 
 type SpotifyPlayerHooks interface {
 	OnNothingPlaying(ctx context.Context, b *bot.Bot)
-	OnNewTrackPlayed(ctx context.Context, b *bot.Bot, track *spoty.CurrentPlaying)
-	OnOldTrackStillPlaying(ctx context.Context, b *bot.Bot, track *spoty.CurrentPlaying)
+	OnNewTrackPlayed(ctx context.Context, b *bot.Bot, track *NowPlaying)
+	OnOldTrackStillPlaying(ctx context.Context, b *bot.Bot, track *NowPlaying)
+	// Reset forgets the sent posts and the post creation backoff, so the next one is sent anyway.
+	Reset()
 }
 
 type spotifyPlayerHookImpl struct {
-	shutdown      <-chan struct{}
 	lastFmClient  *lastfm.Client
+	sourceName    string
+	targets       []*Target
 	onError       func(error) error
 	cachedArtists *expirable.LRU[string, cachedArtistInfo]
 	cachedTracks  *expirable.LRU[string, cachedTrackInfo]
-
-	prevMessage string
 }
 
-func newSpotifyPlayerHookImpl(lastFmClient *lastfm.Client, onError func(error) error, shutdown <-chan struct{}) *spotifyPlayerHookImpl {
+func newSpotifyPlayerHookImpl(lastFmClient *lastfm.Client, sourceName string, targets []*Target, onError func(error) error) *spotifyPlayerHookImpl {
 	h := &spotifyPlayerHookImpl{
 		lastFmClient:  lastFmClient,
+		sourceName:    sourceName,
+		targets:       targets,
 		onError:       onError,
-		shutdown:      shutdown,
 		cachedArtists: expirable.NewLRU[string, cachedArtistInfo](50, nil, 10*time.Minute),
 		cachedTracks:  expirable.NewLRU[string, cachedTrackInfo](50, nil, 10*time.Minute),
 	}
@@ -48,73 +50,71 @@ func (s *spotifyPlayerHookImpl) OnNothingPlaying(ctx context.Context, b *bot.Bot
 	if b == nil {
 		return
 	}
-	msg := buildIdleMessage()
-	s.sendToBot(ctx, b, nil, msg)
+	data := newIdleData(config.C.Idle)
+	for _, target := range s.targets {
+		msg, err := target.templates.Idle(data)
+		if err == nil && target.photo && utf8.RuneCountInString(msg) > maxCaptionLength {
+			err = fmt.Errorf("caption is longer than %d characters", maxCaptionLength)
+		}
+		if err != nil {
+			s.reportError(fmt.Sprintf("target %s: idle template", target.chatID), err)
+			continue
+		}
+		target.send(ctx, b, nil, msg, nil, s.onError)
+	}
 }
 
-func (s *spotifyPlayerHookImpl) OnNewTrackPlayed(ctx context.Context, b *bot.Bot, track *spoty.CurrentPlaying) {
+func (s *spotifyPlayerHookImpl) OnNewTrackPlayed(ctx context.Context, b *bot.Bot, track *NowPlaying) {
 	if b == nil || track == nil {
 		return
 	}
-	artistInfo := s.fetchArtistInfo(ctx, track)
-	trackInfo := s.fetchTrackInfo(ctx, track)
-	msg := buildPlayingMessage(track, artistInfo, trackInfo)
-	s.sendToBot(ctx, b, track, msg)
+	// The user playcount changed since the last play of the track.
+	s.refreshPlays(ctx, track)
+	s.sendPlaying(ctx, b, track)
 }
 
-func (s *spotifyPlayerHookImpl) OnOldTrackStillPlaying(ctx context.Context, b *bot.Bot, track *spoty.CurrentPlaying) {
+func (s *spotifyPlayerHookImpl) OnOldTrackStillPlaying(ctx context.Context, b *bot.Bot, track *NowPlaying) {
 	if b == nil || track == nil {
 		return
 	}
-	artistInfo := s.fetchArtistInfo(ctx, track)
-	trackInfo := s.fetchTrackInfo(ctx, track)
-	msg := buildPlayingMessage(track, artistInfo, trackInfo)
-	s.sendToBot(ctx, b, track, msg)
+	s.sendPlaying(ctx, b, track)
 }
 
-func (s *spotifyPlayerHookImpl) sendToBot(
-	ctx context.Context, b *bot.Bot,
-	track *spoty.CurrentPlaying,
-	msg string,
-) {
-	if b == nil {
-		return
-	}
-
-	if msg == s.prevMessage {
-		return
-	}
-
-	params := &bot.EditMessageTextParams{
-		ChatID:    config.C.Telegram.ChatID,
-		MessageID: config.C.Telegram.MessageID,
-		ParseMode: models.ParseModeMarkdown,
-		Text:      msg,
-		LinkPreviewOptions: &models.LinkPreviewOptions{
-			IsDisabled: bot.True(),
-		},
-	}
-
-	trackID := "???"
-
-	if track != nil && track.ID != "" {
-		trackID = track.ID
-		// Link preview.
-		var opts models.LinkPreviewOptions
-		if track.CoverURL != nil && *track.CoverURL != "" {
-			opts = models.LinkPreviewOptions{
-				IsDisabled:       bot.False(),
-				PreferLargeMedia: bot.True(),
-				URL:              track.CoverURL,
-			}
-			params.LinkPreviewOptions = &opts
+func (s *spotifyPlayerHookImpl) sendPlaying(ctx context.Context, b *bot.Bot, track *NowPlaying) {
+	artistInfo := s.fetchArtistInfo(ctx, track)
+	trackInfo := s.fetchTrackInfo(ctx, track, artistInfo.Name)
+	for _, target := range s.targets {
+		data := newPlayingData(track, s.sourceName, artistInfo, trackInfo, target.playback)
+		var keyboard *models.InlineKeyboardMarkup
+		if target.keyboard {
+			// Links are the buttons, keep the text compact.
+			keyboard = buildKeyboard(data)
+			data.Links = nil
 		}
+		msg, err := target.templates.Playing(data)
+		if err == nil && target.photo {
+			msg, err = fitCaption(target.templates, data, msg)
+		}
+		if err != nil {
+			s.reportError(fmt.Sprintf("target %s: playing template, track %s", target.chatID, track.ID), err)
+			continue
+		}
+		target.send(ctx, b, track, msg, keyboard, s.onError)
 	}
+}
 
-	_, err := b.EditMessageText(ctx, params)
-	if err != nil && s.onError != nil {
-		s.onError(wrapErr(fmt.Sprintf("sendToBot track %s", trackID), err))
+func (s *spotifyPlayerHookImpl) Reset() {
+	for _, target := range s.targets {
+		target.prevMessage = ""
+		target.prevPhoto = ""
+		// Try to create the post right away, like after the rights are fixed.
+		target.createFailures = 0
+		target.createRetryAt = time.Time{}
 	}
+}
 
-	s.prevMessage = msg
+func (s *spotifyPlayerHookImpl) reportError(op string, err error) {
+	if s.onError != nil {
+		s.onError(wrapErr(op, err))
+	}
 }
@@ -0,0 +1,94 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/lastfm"
+)
+
+func TestOnNewTrackPlayedRefreshesPlays(t *testing.T) {
+	plays, albumCalls := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("method") {
+		case "track.getInfo":
+			plays++
+			fmt.Fprintf(w, `{"track":{"name":"Song","userplaycount":"%d"}}`, plays)
+		case "album.getInfo":
+			albumCalls++
+			w.Write([]byte(`{"album":{"name":"Album","wiki":{"summary":"Album story."}}}`))
+		default:
+			w.Write([]byte(`{"error":6,"message":"not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cl := lastfm.NewClient("key")
+	cl.BaseURL = srv.URL + "/2.0/"
+	cl.Limiter = nil
+	cl.Cache = nil
+
+	username := config.C.LastFm.Username
+	config.C.LastFm.Username = "user"
+	t.Cleanup(func() { config.C.LastFm.Username = username })
+
+	ctx := context.Background()
+	b := getBot(t, func(method string) string { return "" })
+	hooks := newSpotifyPlayerHookImpl(cl, "Fake", nil, nil)
+	track := &NowPlaying{ID: "1", Name: "Song", Artist: "Artist", ArtistID: "a", Album: "Album"}
+
+	hooks.OnNewTrackPlayed(ctx, b, track)
+	first, ok := hooks.cachedTracks.Peek(track.ID)
+	if !ok || first.Plays != 1 {
+		t.Fatalf("unexpected first play %+v", first)
+	}
+
+	hooks.OnNewTrackPlayed(ctx, b, track)
+	second, _ := hooks.cachedTracks.Peek(track.ID)
+	if second.Plays != 2 {
+		t.Fatalf("expected 2 plays, got %d", second.Plays)
+	}
+	if second.Emoji != first.Emoji || second.AlbumBlurb != first.AlbumBlurb || albumCalls != 1 {
+		t.Fatalf("expected same track info, got %+v after %+v, %d album calls", second, first, albumCalls)
+	}
+}
+
+func TestFetchArtistInfoCorrection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch query.Get("method") {
+		case "artist.getCorrection":
+			w.Write([]byte(`{"corrections":{"correction":{"artist":{"name":"Guns N' Roses"}}}}`))
+		case "artist.getInfo":
+			if query.Get("artist") != "Guns N' Roses" {
+				t.Errorf("unexpected artist %q", query.Get("artist"))
+			}
+			w.Write([]byte(`{"artist":{"name":"Guns N' Roses","url":"https://www.last.fm/music/Guns+N%27+Roses"}}`))
+		default:
+			w.Write([]byte(`{"error":6,"message":"not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	cl := lastfm.NewClient("key")
+	cl.BaseURL = srv.URL + "/2.0/"
+	cl.Limiter = nil
+	cl.Cache = nil
+
+	ctx := context.Background()
+	hooks := newSpotifyPlayerHookImpl(cl, "Fake", nil, nil)
+	track := &NowPlaying{ID: "1", Name: "Patience", Artist: "guns and roses", ArtistID: "a"}
+
+	artistInfo := hooks.fetchArtistInfo(ctx, track)
+	if artistInfo.Name != "Guns N' Roses" || len(artistInfo.LastFmURL) == 0 {
+		t.Fatalf("unexpected artist info %+v", artistInfo)
+	}
+	trackInfo := hooks.fetchTrackInfo(ctx, track, artistInfo.Name)
+	if trackInfo.FullName != "Guns N' Roses - Patience" {
+		t.Fatalf("unexpected full name %q", trackInfo.FullName)
+	}
+}
@@ -0,0 +1,45 @@
+package spotify
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// buildKeyboard builds the links of the post as inline keyboard:
+// the item on the source, the artist on Last.fm, song.link and YouTube search.
+func buildKeyboard(data *PlayingData) *models.InlineKeyboardMarkup {
+	var (
+		links  []models.InlineKeyboardButton
+		search []models.InlineKeyboardButton
+	)
+	for _, link := range data.Links {
+		if len(link.URL) > 0 {
+			links = append(links, models.InlineKeyboardButton{Text: link.Label, URL: link.URL})
+		}
+	}
+
+	item := data.Item
+	if item.Type == NowPlayingTrack && strings.HasPrefix(item.Link, "https://open.spotify.com/") {
+		// Odesli: the same track on other services.
+		search = append(search, models.InlineKeyboardButton{Text: "song.link", URL: "https://song.link/" + item.Link})
+	}
+	if len(data.FullName) > 0 {
+		search = append(search, models.InlineKeyboardButton{
+			Text: "YouTube",
+			URL:  "https://www.youtube.com/results?search_query=" + url.QueryEscape(data.FullName),
+		})
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for _, row := range [][]models.InlineKeyboardButton{links, search} {
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &models.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
@@ -0,0 +1,22 @@
+package spotify
+
+import "testing"
+
+func TestBuildKeyboard(t *testing.T) {
+	data := samplePlayingData(NowPlayingTrack)
+	data.Item.Link = "https://open.spotify.com/track/1"
+	data.Links[0].URL = data.Item.Link
+
+	keyboard := buildKeyboard(data)
+	if keyboard == nil || len(keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("unexpected keyboard %+v", keyboard)
+	}
+	links, search := keyboard.InlineKeyboard[0], keyboard.InlineKeyboard[1]
+	if len(links) != 2 || links[0].Text != "Sample" || links[1].Text != "Last.fm" {
+		t.Fatalf("unexpected links %+v", links)
+	}
+	if len(search) != 2 || search[0].URL != "https://song.link/https://open.spotify.com/track/1" ||
+		search[1].URL != "https://www.youtube.com/results?search_query=Artist+-+Track+%28Remix%29+%5B2024%5D" {
+		t.Fatalf("unexpected search %+v", search)
+	}
+}
@@ -4,79 +4,202 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/locale"
 	"github.com/oklookat/teletrack/shared"
-	"github.com/oklookat/teletrack/spoty"
 )
 
-func buildPlayingMessage(playing *spoty.CurrentPlaying, artistInfo *cachedArtistInfo, trackInfo *cachedTrackInfo) string {
-	var sb strings.Builder
+// newPlayingData builds the template data.
+func newPlayingData(playing *NowPlaying, source string, artistInfo *cachedArtistInfo, trackInfo *cachedTrackInfo, pb config.SpotifyPlayback) *PlayingData {
+	data := &PlayingData{
+		Time:        locale.FormatTime(time.Now()),
+		Playing:     playing.Playing,
+		Episode:     playing.Type == NowPlayingEpisode,
+		Source:      source,
+		Item:        playing,
+		FullName:    trackInfo.FullName,
+		Playback:    Markdown(formatPlaybackLine(playing, pb)),
+		Bio:         artistInfo.Bio,
+		Tags:        artistInfo.Tags,
+		Similar:     artistInfo.Similar,
+		TagsLine:    Markdown(formatTagsLine(artistInfo.Tags, artistInfo.Similar)),
+		Plays:       trackInfo.Plays,
+		AlbumBlurb:  trackInfo.AlbumBlurb,
+		Description: trackInfo.Description,
+		Emoji:       trackInfo.Emoji,
+	}
+
+	// Unknown for some sources.
+	if playing.DurationMs > 0 {
+		data.Progress = ProgressData{
+			Known:      true,
+			Elapsed:    formatTime(playing.ProgressMs),
+			Duration:   formatTime(playing.DurationMs),
+			Bar:        formatProgressBar(playing.ProgressMs, playing.DurationMs),
+			ProgressMs: playing.ProgressMs,
+			DurationMs: playing.DurationMs,
+		}
+	}
+
+	if len(trackInfo.Link) > 0 {
+		data.Links = append(data.Links, LinkData{Label: source, URL: trackInfo.Link})
+	}
+	if len(artistInfo.LastFmURL) > 0 {
+		data.Links = append(data.Links, LinkData{Label: "Last.fm", URL: artistInfo.LastFmURL})
+	}
+
+	return data
+}
 
-	// Current time.
-	currentTime := shared.TimeToRuWithSeconds(time.Now())
-	sb.WriteString(shared.TgText(currentTime) + "\n\n")
+// newIdleData builds the template data.
+func newIdleData(idle *config.Idle) *IdleData {
+	data := &IdleData{
+		Time: locale.FormatTime(time.Now()),
+		Text: idle.Text,
+	}
+	for _, group := range idle.Groups {
+		groupData := LinkGroupData{}
+		for _, link := range group.Links {
+			groupData.Links = append(groupData.Links, LinkData{
+				Label: strings.TrimSpace(link.Emoji + " " + link.Label),
+				URL:   link.URL,
+			})
+		}
+		data.Groups = append(data.Groups, groupData)
+	}
+	return data
+}
+
+// formatTagsLine formats a line like "Tags: #indie #shoegaze · Similar: X, Y".
+func formatTagsLine(tags []string, similar []LinkData) string {
+	var parts []string
+
+	var hashtags []string
+	for _, tag := range tags {
+		if hashtag := formatHashtag(tag); len(hashtag) > 0 {
+			hashtags = append(hashtags, hashtag)
+		}
+	}
+	if len(hashtags) > 0 {
+		parts = append(parts, shared.TgText(locale.T(locale.LastFmTags, strings.Join(hashtags, " "))))
+	}
 
-	// Track name.
-	status := "▶️"
-	if !playing.Playing {
-		status = "⏸️"
+	if len(similar) > 0 {
+		links := make([]string, 0, len(similar))
+		for _, ar := range similar {
+			if len(ar.URL) > 0 {
+				links = append(links, shared.TgLink(ar.Label, ar.URL))
+			} else {
+				links = append(links, shared.TgText(ar.Label))
+			}
+		}
+		parts = append(parts, shared.TgText(locale.T(locale.LastFmSimilar, ""))+strings.Join(links, ", "))
 	}
-	sb.WriteString(status + " " + trackInfo.TrackName + "\n\n")
 
-	// Progress.
-	progress := fmt.Sprintf("%s %s %s",
-		formatTime(playing.ProgressMs),
-		formatProgressBar(playing.ProgressMs, playing.DurationMs),
-		formatTime(playing.DurationMs))
-	sb.WriteString(progress + "\n\n")
+	return strings.Join(parts, " · ")
+}
 
-	// Bio.
-	if len(artistInfo.Bio) > 0 {
-		sb.WriteString(artistInfo.Bio + "\n\n")
+// formatHashtag converts a tag to a hashtag, like "post-rock" to "#post_rock".
+func formatHashtag(tag string) string {
+	var b strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(tag) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separate = true
+			continue
+		}
+		if separate && b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		separate = false
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return ""
 	}
+	return "#" + b.String()
+}
+
+// formatPlaybackLine formats a line like "from playlist X on device Y 🔀".
+func formatPlaybackLine(playing *NowPlaying, pb config.SpotifyPlayback) string {
+	var parts []string
 
-	// Links.
-	sb.WriteString(trackInfo.SpotifyLink + "\n")
-	if len(artistInfo.LastFmLink) > 0 {
-		sb.WriteString(artistInfo.LastFmLink + "\n\n")
-	} else {
-		sb.WriteString("\n")
+	if pb.Context && playing.Context != nil {
+		if part := formatPlaybackContext(playing.Context); len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	if playing.Device != nil {
+		if pb.Device {
+			parts = append(parts, shared.TgText(locale.T(locale.PlaybackOn, playing.Device.Name)))
+		}
+		if pb.Volume {
+			parts = append(parts, shared.TgText(fmt.Sprintf("🔊 %d%%", playing.Device.VolumePercent)))
+		}
+	}
+	if pb.Shuffle && playing.Shuffle {
+		parts = append(parts, "🔀")
+	}
+	if pb.Repeat {
+		switch playing.Repeat {
+		case "context":
+			parts = append(parts, "🔁")
+		case "track":
+			parts = append(parts, "🔂")
+		}
 	}
 
-	// Emoji.
-	sb.WriteString(trackInfo.Emoji + "\n")
-	// powered by
-	sb.WriteString(shared.TgLink("powered by oklookat/teletrack", "https://github.com/oklookat/teletrack"))
+	return strings.Join(parts, " ")
+}
 
-	return sb.String()
+func formatPlaybackContext(pbCtx *PlaybackContext) string {
+	kind := contextKind(pbCtx.Type)
+	name := pbCtx.Name
+	if pbCtx.Type == ContextTypeCollection {
+		// "from Liked Songs", not "from collection Liked Songs".
+		kind = ""
+		name = locale.T(locale.ContextCollection)
+	}
+	if len(name) == 0 {
+		if len(kind) == 0 {
+			return ""
+		}
+		return shared.TgText(locale.T(locale.PlaybackFrom, kind))
+	}
+
+	prefix := locale.T(locale.PlaybackFrom, "")
+	if len(kind) > 0 {
+		prefix = locale.T(locale.PlaybackFrom, kind+" ")
+	}
+	if len(pbCtx.Link) > 0 {
+		return shared.TgText(prefix) + shared.TgLink(name, pbCtx.Link)
+	}
+	return shared.TgText(prefix + name)
 }
 
-func buildIdleMessage() string {
-	currentTime := shared.TimeToRuWithSeconds(time.Now())
-	links := []string{
-		shared.TgText("✉️ @dvdqr"),
-		shared.TgText("✉️ oklocate@gmail.com"),
-		"",
-		shared.TgLink("💰 Донат (DA)", "https://donationalerts.com/r/oklookat"),
-		shared.TgLink("💰 Донат (Boosty)", "https://boosty.to/oklookat/donate"),
-		"",
-		shared.TgLink("💻 GitHub", "https://github.com/oklookat"),
-		"",
-		shared.TgLink("🎧 Spotify", "https://open.spotify.com/user/60c4lc5cwaesypcv9mvzb1klf"),
-		shared.TgLink("🎧 Last.fm", "https://last.fm/user/ndskmusic"),
-		"",
-		shared.TgLink("🍿 Кинопоиск", "https://kinopoisk.ru/user/166758523"),
-	}
-
-	var sb strings.Builder
-	sb.WriteString(shared.TgText(currentTime))
-	sb.WriteString("\n\n")
-	sb.WriteString(strings.Join(links, "\n"))
-	return sb.String()
+// contextKind returns the localized context type.
+func contextKind(typ string) string {
+	switch typ {
+	case ContextTypeAlbum:
+		return locale.T(locale.ContextAlbum)
+	case ContextTypeArtist:
+		return locale.T(locale.ContextArtist)
+	case ContextTypePlaylist:
+		return locale.T(locale.ContextPlaylist)
+	case ContextTypeShow:
+		return locale.T(locale.ContextShow)
+	}
+	return typ
 }
 
 func formatTime(ms int) string {
 	totalSec := ms / 1000
+	if totalSec >= 3600 {
+		// Podcasts.
+		return fmt.Sprintf("%d:%02d:%02d", totalSec/3600, totalSec%3600/60, totalSec%60)
+	}
 	return fmt.Sprintf("%02d:%02d", totalSec/60, totalSec%60)
 }
 
@@ -3,80 +3,207 @@ package spotify
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/oklookat/teletrack/config"
 	"github.com/oklookat/teletrack/lastfm"
-	"github.com/oklookat/teletrack/spoty"
-	spotifyapi "github.com/zmb3/spotify/v2"
+	"github.com/oklookat/teletrack/telegram"
 )
 
 const (
 	rateLimitSec     = 4
 	rateLimit        = rateLimitSec * time.Second
 	lastProgressIdle = 3 * (rateLimit / 2)
+	watchRetryDelay  = 10 * time.Second
 )
 
 type Player struct {
-	client   *spotifyapi.Client
-	hooks    SpotifyPlayerHooks
-	onError  func(error) error
+	source  NowPlayingSource
+	hooks   SpotifyPlayerHooks
+	onError func(error) error
+	wg      sync.WaitGroup
+
+	// lifeMu guards the lifecycle. shutdown is nil while stopped.
+	lifeMu   sync.Mutex
 	shutdown chan struct{}
-	wg       sync.WaitGroup
+	// control passes commands to the monitor loop.
+	control chan playerControl
+	paused  atomic.Bool
+
+	// State for commands, the loop holds the main lock while posting.
+	statusMu    sync.Mutex
+	startedAt   time.Time
+	current     *NowPlaying
+	lastPoll    time.Time
+	lastErr     error
+	lastErrTime time.Time
 
 	sync.RWMutex
-	lastPlayed       *spoty.CurrentPlaying
+	lastPlayed       *NowPlaying
 	lastProgressTime time.Time
 }
 
-func NewPlayer(client *spotifyapi.Client, onError func(error) error) *Player {
+func NewPlayer(source NowPlayingSource, targets []*Target, onError func(error) error) *Player {
 	player := &Player{
-		client:   client,
-		onError:  onError,
-		shutdown: make(chan struct{}),
+		source:  source,
+		onError: onError,
+		control: make(chan playerControl, 1),
 	}
-	player.hooks = newSpotifyPlayerHookImpl(lastfm.NewClient(config.C.LastFm.APIKey), onError, player.shutdown)
+	player.hooks = newSpotifyPlayerHookImpl(lastfm.NewClient(config.C.LastFm.APIKey), source.Name(), targets, onError)
 	return player
 }
 
-func (p *Player) Handle(ctx context.Context, b *bot.Bot) {
+func (p *Player) Name() string {
+	return "player"
+}
+
+// Start starts monitoring. The post is sent anew after the start, even if it was paused.
+func (p *Player) Start(ctx context.Context, b *bot.Bot) error {
+	p.lifeMu.Lock()
+	defer p.lifeMu.Unlock()
+	if p.shutdown != nil {
+		return telegram.ErrModuleRunning
+	}
+
+	p.statusMu.Lock()
+	p.startedAt = time.Now()
+	p.statusMu.Unlock()
+
+	p.Lock()
+	p.lastPlayed = nil
+	if p.hooks != nil {
+		p.hooks.Reset()
+	}
+	p.Unlock()
+	// Otherwise a restart after /pause or /idle never updates the post.
+	p.paused.Store(false)
+
+	// Drop the command sent before the stop.
+	select {
+	case <-p.control:
+	default:
+	}
+
+	p.shutdown = make(chan struct{})
 	p.wg.Add(1)
-	go p.monitorLoop(ctx, b)
+	go p.monitorLoop(ctx, b, p.shutdown)
+	return nil
 }
 
-func (p *Player) Shutdown() {
+// Stop stops monitoring, and waits until the loops are done.
+func (p *Player) Stop() {
+	p.lifeMu.Lock()
+	defer p.lifeMu.Unlock()
+	if p.shutdown == nil {
+		return
+	}
 	close(p.shutdown)
 	p.wg.Wait()
+	p.shutdown = nil
+}
+
+func (p *Player) Status() telegram.ModuleState {
+	p.lifeMu.Lock()
+	defer p.lifeMu.Unlock()
+	if p.shutdown == nil {
+		return telegram.ModuleStopped
+	}
+	return telegram.ModuleRunning
 }
 
-func (p *Player) monitorLoop(ctx context.Context, b *bot.Bot) {
+func (p *Player) monitorLoop(ctx context.Context, b *bot.Bot, shutdown <-chan struct{}) {
+	// Runs after wg.Done, because Stop waits for wg with the lock held.
+	defer p.clearShutdown(shutdown)
 	defer p.wg.Done()
 	ticker := time.NewTicker(rateLimit)
 	defer ticker.Stop()
 
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer cancelWatch()
+	changes := make(chan struct{}, 1)
+	if watcher, ok := p.source.(NowPlayingWatcher); ok {
+		p.wg.Add(1)
+		go p.watchLoop(watchCtx, watcher, changes)
+	}
+
 	for {
 		select {
-		case <-p.shutdown:
+		case <-shutdown:
 			return
+		case <-changes:
+			p.tick(ctx, b, false)
+		case ctl := <-p.control:
+			p.handleControl(ctx, b, ctl)
 		case <-ctx.Done():
 			if p.onError != nil {
 				p.onError(ctx.Err())
 			}
 			return
 		case <-ticker.C:
-			if err := p.handleTick(ctx, b); err != nil && p.onError != nil {
-				p.onError(err)
-			}
+			p.tick(ctx, b, false)
 		}
 	}
 }
 
+// clearShutdown marks the player stopped after the loop is done by ctx,
+// unless it was stopped or started again meanwhile.
+func (p *Player) clearShutdown(shutdown <-chan struct{}) {
+	p.lifeMu.Lock()
+	defer p.lifeMu.Unlock()
+	if p.shutdown == shutdown {
+		p.shutdown = nil
+	}
+}
+
+// watchLoop keeps the source watched, restarting the watch after failures.
+func (p *Player) watchLoop(ctx context.Context, watcher NowPlayingWatcher, changes chan<- struct{}) {
+	defer p.wg.Done()
+	for {
+		err := watcher.Watch(ctx, changes)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && p.onError != nil {
+			p.onError(wrapErr("watch source", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+// tick polls the source and updates the post, unless paused or forced.
+func (p *Player) tick(ctx context.Context, b *bot.Bot, force bool) {
+	if p.paused.Load() && !force {
+		return
+	}
+	err := p.handleTick(ctx, b)
+
+	p.statusMu.Lock()
+	p.lastPoll = time.Now()
+	if err != nil {
+		p.lastErr = err
+		p.lastErrTime = p.lastPoll
+	}
+	p.statusMu.Unlock()
+
+	if err != nil && p.onError != nil {
+		p.onError(err)
+	}
+}
+
 func (p *Player) handleTick(ctx context.Context, b *bot.Bot) error {
-	currentPlaying, err := spoty.GetCurrentPlaying(ctx, p.client)
+	currentPlaying, err := p.source.NowPlaying(ctx)
 	if err != nil {
 		return wrapErr("get current playing", err)
 	}
+	p.statusMu.Lock()
+	p.current = currentPlaying
+	p.statusMu.Unlock()
 	if p.hooks == nil {
 		return nil
 	}
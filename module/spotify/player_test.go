@@ -0,0 +1,191 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/oklookat/teletrack/telegram"
+)
+
+type fakeSource struct {
+	playing *NowPlaying
+	err     error
+}
+
+func (f *fakeSource) Name() string {
+	return "Fake"
+}
+
+func (f *fakeSource) NowPlaying(ctx context.Context) (*NowPlaying, error) {
+	return f.playing, f.err
+}
+
+type fakeHooks struct {
+	calls []string
+}
+
+func (f *fakeHooks) OnNothingPlaying(ctx context.Context, b *bot.Bot) {
+	f.calls = append(f.calls, "nothing")
+}
+
+func (f *fakeHooks) OnNewTrackPlayed(ctx context.Context, b *bot.Bot, track *NowPlaying) {
+	f.calls = append(f.calls, "new:"+track.ID)
+}
+
+func (f *fakeHooks) OnOldTrackStillPlaying(ctx context.Context, b *bot.Bot, track *NowPlaying) {
+	f.calls = append(f.calls, "old:"+track.ID)
+}
+
+func (f *fakeHooks) Reset() {
+	f.calls = append(f.calls, "reset")
+}
+
+func getPlayer(source NowPlayingSource) (*Player, *fakeHooks) {
+	hooks := &fakeHooks{}
+	player := NewPlayer(source, nil, nil)
+	player.hooks = hooks
+	return player, hooks
+}
+
+func TestPlayerHandleTick(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{}
+	player, hooks := getPlayer(source)
+
+	steps := []*NowPlaying{
+		nil,
+		{ID: "1", Playing: true},
+		{ID: "1", Playing: true},
+		{ID: "2", Playing: true},
+		{ID: "2", Playing: false},
+		nil,
+	}
+	for _, step := range steps {
+		source.playing = step
+		if err := player.handleTick(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"nothing", "new:1", "old:1", "new:2", "old:2", "nothing"}
+	if !reflect.DeepEqual(hooks.calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, hooks.calls)
+	}
+}
+
+func TestPlayerHandleTickError(t *testing.T) {
+	sourceErr := errors.New("source down")
+	player, hooks := getPlayer(&fakeSource{err: sourceErr})
+
+	if err := player.handleTick(context.Background(), nil); !errors.Is(err, sourceErr) {
+		t.Fatalf("expected source error, got %v", err)
+	}
+	if len(hooks.calls) > 0 {
+		t.Fatalf("hooks called on error: %v", hooks.calls)
+	}
+}
+
+func TestPlayerCommands(t *testing.T) {
+	ctx := context.Background()
+	source := &fakeSource{playing: &NowPlaying{ID: "1", Name: "Song", Artist: "Artist", Playing: true}}
+	player, hooks := getPlayer(source)
+
+	if reply := player.cmdPause(ctx, ""); reply != "Module player is not running, use /startmodule player." || player.paused.Load() {
+		t.Fatalf("unexpected /pause of stopped player: %q", reply)
+	}
+	if reply := player.cmdResume(ctx, ""); reply != "Module player is not running, use /startmodule player." {
+		t.Fatalf("unexpected /resume of stopped player: %q", reply)
+	}
+
+	if status := player.cmdStatus(ctx, ""); !strings.Contains(status, "Updates: stopped") {
+		t.Fatalf("unexpected /status of stopped player: %q", status)
+	}
+
+	player.paused.Store(true)
+	player.tick(ctx, nil, false)
+	if len(hooks.calls) > 0 {
+		t.Fatalf("hooks called while paused: %v", hooks.calls)
+	}
+
+	if reply := player.cmdRefresh(ctx, ""); reply != "Module player is not running, use /startmodule player." || len(player.control) > 0 {
+		t.Fatalf("unexpected /refresh of stopped player: %q", reply)
+	}
+	player.handleControl(ctx, nil, controlRefresh)
+	player.paused.Store(false)
+	player.tick(ctx, nil, false)
+
+	expected := []string{"reset", "new:1", "old:1"}
+	if !reflect.DeepEqual(hooks.calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, hooks.calls)
+	}
+	if now := player.cmdNow(ctx, ""); now != "🎵 Artist - Song" {
+		t.Fatalf("unexpected /now %q", now)
+	}
+}
+
+func TestPlayerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	player, _ := getPlayer(&fakeSource{})
+
+	for range 2 {
+		if err := player.Start(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := player.Start(ctx, nil); !errors.Is(err, telegram.ErrModuleRunning) {
+			t.Fatalf("expected running error, got %v", err)
+		}
+		if player.Status() != telegram.ModuleRunning {
+			t.Fatal("expected running")
+		}
+		player.Stop()
+		if player.Status() != telegram.ModuleStopped {
+			t.Fatal("expected stopped")
+		}
+	}
+}
+
+func TestPlayerStartResumes(t *testing.T) {
+	ctx := context.Background()
+	player, hooks := getPlayer(&fakeSource{playing: &NowPlaying{ID: "1", Playing: true}})
+	if err := player.Start(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	player.cmdPause(ctx, "")
+	player.Stop()
+
+	if err := player.Start(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	player.Stop()
+	hooks.calls = nil
+	player.tick(ctx, nil, false)
+	if len(hooks.calls) == 0 {
+		t.Fatal("expected hooks called after restart")
+	}
+}
+
+func TestPlayerContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	player, _ := getPlayer(&fakeSource{})
+	if err := player.Start(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for player.Status() != telegram.ModuleStopped {
+		if time.Now().After(deadline) {
+			t.Fatal("expected stopped after ctx is done")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := player.Start(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	player.Stop()
+}
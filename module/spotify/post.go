@@ -0,0 +1,398 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/oklookat/teletrack/config"
+)
+
+// Target is a post updated with the playing item.
+// Every target has own templates, toggles and de-duplication state.
+// Failed post creation is retried with the backoff between these delays.
+const (
+	createPostMinDelay = time.Minute
+	createPostMaxDelay = time.Hour
+)
+
+type Target struct {
+	chatID           string
+	messageID        int
+	pin              bool
+	photo            bool
+	keyboard         bool
+	placeholderPhoto string
+	templates        *Templates
+	playback         config.SpotifyPlayback
+	// save persists the ID of the created post.
+	save func(messageID int) error
+
+	prevMessage string
+	// Photo of the post in photo mode.
+	prevPhoto string
+	// createFailures in a row, the first one is reported.
+	createFailures int
+	// createRetryAt is when the failed post creation is tried again.
+	createRetryAt time.Time
+}
+
+// LoadTargets creates the targets from config, and validates their templates.
+// Without telegram.targets, the post of the telegram section is the only target.
+func LoadTargets(cfg *config.Config) ([]*Target, error) {
+	tgCfg := cfg.Telegram
+	if len(tgCfg.Targets) == 0 {
+		templates, err := LoadTemplates(cfg.Templates)
+		if err != nil {
+			return nil, err
+		}
+		photo := tgCfg.PostMode == config.PostModePhoto
+		if photo {
+			if err := validateIdleCaption(templates, cfg.Idle); err != nil {
+				return nil, err
+			}
+		}
+		return []*Target{{
+			chatID:           tgCfg.ChatID,
+			messageID:        tgCfg.MessageID,
+			pin:              tgCfg.Pin,
+			photo:            photo,
+			keyboard:         tgCfg.Keyboard,
+			placeholderPhoto: tgCfg.PlaceholderPhoto,
+			templates:        templates,
+			playback:         cfg.Spotify.Playback,
+			save: func(messageID int) error {
+				return config.C.Update(func(c *config.Config) {
+					c.Telegram.MessageID = messageID
+				})
+			},
+		}}, nil
+	}
+
+	targets := make([]*Target, 0, len(tgCfg.Targets))
+	for i, targetCfg := range tgCfg.Targets {
+		templates, err := LoadTemplates(mergeTemplates(cfg.Templates, targetCfg.Templates))
+		if err != nil {
+			return nil, fmt.Errorf("target %d (%s): %w", i, targetCfg.ChatID, err)
+		}
+		photo := targetCfg.PostMode == config.PostModePhoto
+		if photo {
+			if err := validateIdleCaption(templates, cfg.Idle); err != nil {
+				return nil, fmt.Errorf("target %d (%s): %w", i, targetCfg.ChatID, err)
+			}
+		}
+		playback := cfg.Spotify.Playback
+		if targetCfg.Playback != nil {
+			playback = *targetCfg.Playback
+		}
+		targets = append(targets, &Target{
+			chatID:           targetCfg.ChatID,
+			messageID:        targetCfg.MessageID,
+			pin:              targetCfg.Pin,
+			photo:            photo,
+			keyboard:         targetCfg.Keyboard,
+			placeholderPhoto: targetCfg.PlaceholderPhoto,
+			templates:        templates,
+			playback:         playback,
+			save: func(messageID int) error {
+				return config.C.Update(func(c *config.Config) {
+					targetCfg.MessageID = messageID
+				})
+			},
+		})
+	}
+	return targets, nil
+}
+
+// validateIdleCaption renders the idle post of the photo target, which is sent as the caption.
+// Unlike the playing post, it can't be shortened on send.
+func validateIdleCaption(templates *Templates, idle *config.Idle) error {
+	if idle == nil {
+		idle = &config.Idle{}
+	}
+	msg, err := templates.Idle(newIdleData(idle))
+	if err != nil {
+		return fmt.Errorf("idle template: %w", err)
+	}
+	if utf8.RuneCountInString(msg) > maxCaptionLength {
+		return fmt.Errorf("idle template: caption is longer than %d characters", maxCaptionLength)
+	}
+	return nil
+}
+
+// mergeTemplates takes the target templates, and the global ones for the empty.
+func mergeTemplates(global, target *config.Templates) *config.Templates {
+	merged := &config.Templates{}
+	if global != nil {
+		*merged = *global
+	}
+	if target == nil {
+		return merged
+	}
+	if len(target.Playing) > 0 || len(target.PlayingFile) > 0 {
+		merged.Playing, merged.PlayingFile = target.Playing, target.PlayingFile
+	}
+	if len(target.Idle) > 0 || len(target.IdleFile) > 0 {
+		merged.Idle, merged.IdleFile = target.Idle, target.IdleFile
+	}
+	return merged
+}
+
+// fitCaption drops the longest optional parts, until the message fits the caption.
+func fitCaption(templates *Templates, data *PlayingData, msg string) (string, error) {
+	shorten := []func(){
+		func() { data.AlbumBlurb = "" },
+		func() { data.TagsLine = "" },
+		func() { data.Bio = "" },
+		func() { data.Description = "" },
+		func() { data.Playback = "" },
+	}
+	for _, fn := range shorten {
+		if utf8.RuneCountInString(msg) <= maxCaptionLength {
+			return msg, nil
+		}
+		fn()
+		var err error
+		if msg, err = templates.Playing(data); err != nil {
+			return "", err
+		}
+	}
+	if utf8.RuneCountInString(msg) > maxCaptionLength {
+		return "", fmt.Errorf("caption is longer than %d characters", maxCaptionLength)
+	}
+	return msg, nil
+}
+
+func (t *Target) send(
+	ctx context.Context, b *bot.Bot,
+	track *NowPlaying,
+	msg string,
+	keyboard *models.InlineKeyboardMarkup,
+	onError func(error) error,
+) {
+	if b == nil {
+		return
+	}
+	if time.Now().Before(t.createRetryAt) {
+		// The post can't be created, don't call the API on every tick.
+		return
+	}
+
+	// Without keyboard, edit removes the previous one.
+	var markup models.ReplyMarkup
+	if keyboard != nil {
+		markup = keyboard
+	}
+
+	var err error
+	if t.photo {
+		err = t.sendPhoto(ctx, b, track, msg, markup)
+	} else {
+		err = t.sendText(ctx, b, track, msg, markup)
+	}
+	if err != nil && onError != nil {
+		trackID := "???"
+		if track != nil && track.ID != "" {
+			trackID = track.ID
+		}
+		onError(wrapErr(fmt.Sprintf("target %s: sendToBot track %s", t.chatID, trackID), err))
+	}
+}
+
+func (t *Target) sendText(ctx context.Context, b *bot.Bot, track *NowPlaying, msg string, markup models.ReplyMarkup) error {
+	if msg == t.prevMessage {
+		return nil
+	}
+
+	params := &bot.EditMessageTextParams{
+		ChatID:    t.chatID,
+		MessageID: t.messageID,
+		ParseMode: models.ParseModeMarkdown,
+		Text:      msg,
+		LinkPreviewOptions: &models.LinkPreviewOptions{
+			IsDisabled: bot.True(),
+		},
+		ReplyMarkup: markup,
+	}
+
+	// Link preview.
+	if track != nil && track.CoverURL != nil && *track.CoverURL != "" {
+		params.LinkPreviewOptions = &models.LinkPreviewOptions{
+			IsDisabled:       bot.False(),
+			PreferLargeMedia: bot.True(),
+			URL:              track.CoverURL,
+		}
+	}
+
+	if params.MessageID == 0 {
+		return t.createPost(ctx, b, msg, "", params.LinkPreviewOptions, markup)
+	}
+	_, err := b.EditMessageText(ctx, params)
+	if isMessageGone(err) || isWrongPostKind(err) {
+		return t.createPost(ctx, b, msg, "", params.LinkPreviewOptions, markup)
+	}
+	if err != nil && !isNotModified(err) {
+		return err
+	}
+	t.setSent(msg, "")
+	return nil
+}
+
+// sendPhoto updates the photo post: the photo is replaced only if the cover changed,
+// otherwise just the caption.
+func (t *Target) sendPhoto(ctx context.Context, b *bot.Bot, track *NowPlaying, msg string, markup models.ReplyMarkup) error {
+	photo := t.placeholderPhoto
+	if track != nil && track.CoverURL != nil && *track.CoverURL != "" {
+		photo = *track.CoverURL
+	}
+
+	var err error
+	switch {
+	case t.messageID == 0:
+		return t.createPost(ctx, b, msg, photo, nil, markup)
+	case len(photo) > 0 && photo != t.prevPhoto:
+		_, err = b.EditMessageMedia(ctx, &bot.EditMessageMediaParams{
+			ChatID:    t.chatID,
+			MessageID: t.messageID,
+			Media: &models.InputMediaPhoto{
+				Media:     photo,
+				Caption:   msg,
+				ParseMode: models.ParseModeMarkdown,
+			},
+			ReplyMarkup: markup,
+		})
+	case msg != t.prevMessage:
+		_, err = b.EditMessageCaption(ctx, &bot.EditMessageCaptionParams{
+			ChatID:      t.chatID,
+			MessageID:   t.messageID,
+			Caption:     msg,
+			ParseMode:   models.ParseModeMarkdown,
+			ReplyMarkup: markup,
+		})
+	default:
+		return nil
+	}
+	if isMessageGone(err) || isWrongPostKind(err) {
+		return t.createPost(ctx, b, msg, photo, nil, markup)
+	}
+	if err != nil && !isNotModified(err) {
+		// Keep the previous state, so the next tick tries again.
+		return err
+	}
+	t.setSent(msg, photo)
+	return nil
+}
+
+// setSent remembers the sent post, so it's not sent again.
+// Called only after success, otherwise a failed update is never retried.
+func (t *Target) setSent(msg, photo string) {
+	t.prevMessage = msg
+	if len(photo) > 0 {
+		t.prevPhoto = photo
+	}
+}
+
+// isMessageGone reports whether the edited post doesn't exist anymore.
+func isMessageGone(err error) bool {
+	return errors.Is(err, bot.ErrorBadRequest) && strings.Contains(err.Error(), "message to edit not found")
+}
+
+// isWrongPostKind reports whether the post can't be edited in the post mode,
+// like a text post after switching to photo mode.
+func isWrongPostKind(err error) bool {
+	if !errors.Is(err, bot.ErrorBadRequest) {
+		return false
+	}
+	desc := err.Error()
+	return strings.Contains(desc, "no media") ||
+		strings.Contains(desc, "no caption") ||
+		strings.Contains(desc, "no text in the message")
+}
+
+// isNotModified reports whether the post already has the content.
+func isNotModified(err error) bool {
+	return errors.Is(err, bot.ErrorBadRequest) && strings.Contains(err.Error(), "message is not modified")
+}
+
+// createPostRetryDelay is the delay after the failures of post creation in a row:
+// a minute, doubled with every failure up to an hour.
+func createPostRetryDelay(failures int) time.Duration {
+	delay := createPostMinDelay
+	for i := 1; i < failures && delay < createPostMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, createPostMaxDelay)
+}
+
+// createPost sends a new post instead of the missing (or wrong kind) one, pins it if configured,
+// and saves its ID to config. In photo mode, the post can't be created without a photo.
+func (t *Target) createPost(ctx context.Context, b *bot.Bot, msg, photo string, preview *models.LinkPreviewOptions, markup models.ReplyMarkup) error {
+	oldID := t.messageID
+
+	var (
+		sent *models.Message
+		err  error
+	)
+	if t.photo {
+		if len(photo) == 0 {
+			err = errors.New("can't create photo post without photo, set placeholderPhoto")
+		} else {
+			sent, err = b.SendPhoto(ctx, &bot.SendPhotoParams{
+				ChatID:      t.chatID,
+				Photo:       &models.InputFileString{Data: photo},
+				Caption:     msg,
+				ParseMode:   models.ParseModeMarkdown,
+				ReplyMarkup: markup,
+			})
+		}
+	} else {
+		sent, err = b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:             t.chatID,
+			Text:               msg,
+			ParseMode:          models.ParseModeMarkdown,
+			LinkPreviewOptions: preview,
+			ReplyMarkup:        markup,
+		})
+	}
+	if err != nil {
+		err = fmt.Errorf("create post: %w", err)
+		t.createFailures++
+		t.createRetryAt = time.Now().Add(createPostRetryDelay(t.createFailures))
+		if t.createFailures > 1 {
+			// Most likely no rights in the chat. Reported once, don't spam the service chat.
+			slog.Error("failed to create post", "chatID", t.chatID, "err", err, "retryAt", t.createRetryAt)
+			return nil
+		}
+		return err
+	}
+	t.createFailures = 0
+	t.createRetryAt = time.Time{}
+	t.messageID = sent.ID
+	t.setSent(msg, photo)
+
+	if t.pin {
+		if _, err := b.PinChatMessage(ctx, &bot.PinChatMessageParams{
+			ChatID:              t.chatID,
+			MessageID:           sent.ID,
+			DisableNotification: true,
+		}); err != nil {
+			slog.Warn("failed to pin post", "chatID", t.chatID, "err", err)
+		}
+	}
+
+	if err := t.save(sent.ID); err != nil {
+		return fmt.Errorf("save post ID %d: %w", sent.ID, err)
+	}
+
+	if oldID == 0 {
+		slog.Info("post created", "chatID", t.chatID, "messageID", sent.ID)
+		return nil
+	}
+	return fmt.Errorf("post %d can't be updated, created new post %d", oldID, sent.ID)
+}
@@ -0,0 +1,215 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/oklookat/teletrack/config"
+)
+
+// getBot creates a bot of a fake Bot API. handler returns the error description, or "" for success.
+func getBot(t *testing.T, handler func(method string) string) *bot.Bot {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if desc := handler(path.Base(r.URL.Path)); len(desc) > 0 {
+			fmt.Fprintf(w, `{"ok":false,"error_code":400,"description":%q}`, desc)
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":2,"date":0,"chat":{"id":1,"type":"channel"}}}`))
+	}))
+	t.Cleanup(srv.Close)
+	b, err := bot.New("token", bot.WithServerURL(srv.URL), bot.WithSkipGetMe())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestIsMessageGone(t *testing.T) {
+	gone := fmt.Errorf("%w, %s", bot.ErrorBadRequest, "Bad Request: message to edit not found")
+	if !isMessageGone(gone) {
+		t.Fatal("expected gone")
+	}
+	if !isMessageGone(wrapErr("sendToBot", gone)) {
+		t.Fatal("expected gone through wrap")
+	}
+	notModified := fmt.Errorf("%w, %s", bot.ErrorBadRequest, "Bad Request: message is not modified")
+	if isMessageGone(notModified) || isMessageGone(errors.New("message to edit not found")) || isMessageGone(nil) {
+		t.Fatal("unexpected gone")
+	}
+	if !isNotModified(notModified) || isNotModified(gone) {
+		t.Fatal("unexpected not modified")
+	}
+}
+
+func TestSendPhotoRetry(t *testing.T) {
+	var calls []string
+	failMedia := true
+	b := getBot(t, func(method string) string {
+		calls = append(calls, method)
+		if method == "editMessageMedia" && failMedia {
+			return "Bad Request: wrong file identifier/HTTP URL specified"
+		}
+		return ""
+	})
+
+	cover := "https://example.com/cover.jpg"
+	track := &NowPlaying{ID: "1", CoverURL: &cover}
+	target := &Target{chatID: "@channel", messageID: 1, photo: true}
+	ctx := context.Background()
+
+	if err := target.sendPhoto(ctx, b, track, "caption", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	// Same caption, but the photo must be sent again.
+	failMedia = false
+	if err := target.sendPhoto(ctx, b, track, "caption", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.sendPhoto(ctx, b, track, "caption", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"editMessageMedia", "editMessageMedia"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+}
+
+func TestSendPhotoToTextPost(t *testing.T) {
+	var calls []string
+	b := getBot(t, func(method string) string {
+		calls = append(calls, method)
+		if method == "editMessageMedia" {
+			return "Bad Request: there is no media in the message to edit"
+		}
+		return ""
+	})
+
+	cover := "https://example.com/cover.jpg"
+	target := &Target{chatID: "@channel", messageID: 1, photo: true, save: func(int) error { return nil }}
+	err := target.sendPhoto(context.Background(), b, &NowPlaying{ID: "1", CoverURL: &cover}, "caption", nil)
+	if err == nil || !strings.Contains(err.Error(), "created new post 2") {
+		t.Fatalf("expected new post, got %v", err)
+	}
+	if target.messageID != 2 || target.prevPhoto != cover {
+		t.Fatalf("unexpected target %+v", target)
+	}
+	if expected := "editMessageMedia,sendPhoto"; strings.Join(calls, ",") != expected {
+		t.Fatalf("expected %s, got %v", expected, calls)
+	}
+}
+
+func TestCreatePostBackoff(t *testing.T) {
+	var calls []string
+	b := getBot(t, func(method string) string {
+		calls = append(calls, method)
+		return "Bad Request: chat not found"
+	})
+
+	target := &Target{chatID: "@channel"}
+	var errs []error
+	onError := func(err error) error {
+		errs = append(errs, err)
+		return nil
+	}
+	ctx := context.Background()
+	for range 3 {
+		target.send(ctx, b, nil, "text", nil, onError)
+	}
+	if len(calls) != 1 || len(errs) != 1 {
+		t.Fatalf("expected one call and error, got %v, %v", calls, errs)
+	}
+
+	// Retried after the delay, without another report.
+	target.createRetryAt = time.Now()
+	target.send(ctx, b, nil, "text", nil, onError)
+	if len(calls) != 2 || len(errs) != 1 || target.createFailures != 2 {
+		t.Fatalf("expected silent retry, got %v, %v", calls, errs)
+	}
+	if delay := time.Until(target.createRetryAt); delay <= createPostMinDelay {
+		t.Fatalf("expected growing delay, got %s", delay)
+	}
+	if createPostRetryDelay(100) != createPostMaxDelay {
+		t.Fatal("expected max delay")
+	}
+}
+
+func TestFitCaption(t *testing.T) {
+	tmpls, err := LoadTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := samplePlayingData(NowPlayingTrack)
+	data.Bio = strings.Repeat("bio ", 300)
+	msg, err := tmpls.Playing(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err = fitCaption(tmpls, data, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(msg, "bio") || !strings.Contains(msg, "Playlist") {
+		t.Fatalf("expected only bio dropped:\n%s", msg)
+	}
+}
+
+func TestLoadTargets(t *testing.T) {
+	cfg := &config.Config{
+		Telegram: &config.Telegram{
+			ChatID: "@ignored",
+			Targets: []*config.Target{
+				{ChatID: "@channel", MessageID: 1},
+				{
+					ChatID:    "@group",
+					PostMode:  config.PostModePhoto,
+					Templates: &config.Templates{Playing: `{{.FullName}}`},
+					Playback:  &config.SpotifyPlayback{Device: true},
+				},
+			},
+		},
+		Spotify:   &config.Spotify{Playback: config.SpotifyPlayback{Context: true}},
+		Templates: &config.Templates{},
+	}
+
+	targets, err := LoadTargets(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || targets[0].chatID != "@channel" || targets[0].messageID != 1 || targets[0].photo {
+		t.Fatalf("unexpected targets %+v", targets)
+	}
+	if !targets[1].photo || !targets[1].playback.Device || targets[1].playback.Context {
+		t.Fatalf("unexpected target %+v", targets[1])
+	}
+	msg, err := targets[1].templates.Playing(samplePlayingData(NowPlayingTrack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg != `Artist \- Track \(Remix\) \[2024\]` {
+		t.Fatalf("unexpected message %q", msg)
+	}
+
+	cfg.Telegram.Targets[1].Templates.Playing = `{{.Nope}}`
+	if _, err := LoadTargets(cfg); err == nil {
+		t.Fatal("expected template error")
+	}
+
+	cfg.Telegram.Targets[1].Templates.Playing = ""
+	cfg.Idle = &config.Idle{Text: strings.Repeat("a", maxCaptionLength)}
+	if _, err := LoadTargets(cfg); err == nil || !strings.Contains(err.Error(), "caption") {
+		t.Fatalf("expected caption error, got %v", err)
+	}
+	cfg.Telegram.Targets[1].PostMode = ""
+	if _, err := LoadTargets(cfg); err != nil {
+		t.Fatalf("unexpected error of text target %v", err)
+	}
+}
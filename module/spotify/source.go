@@ -0,0 +1,91 @@
+package spotify
+
+import (
+	"context"
+
+	"github.com/oklookat/teletrack/spoty"
+)
+
+// NowPlayingType is the type of the playing item.
+type NowPlayingType string
+
+const (
+	NowPlayingTrack NowPlayingType = "track"
+	// NowPlayingEpisode is a podcast episode or an audiobook chapter.
+	NowPlayingEpisode NowPlayingType = "episode"
+)
+
+// Context types, the same as Spotify ones. Sources may use other types too.
+const (
+	ContextTypeAlbum      = spoty.ContextTypeAlbum
+	ContextTypeArtist     = spoty.ContextTypeArtist
+	ContextTypePlaylist   = spoty.ContextTypePlaylist
+	ContextTypeShow       = spoty.ContextTypeShow
+	ContextTypeCollection = spoty.ContextTypeCollection
+)
+
+// NowPlayingSource provides the item playing now.
+type NowPlayingSource interface {
+	// Name of the source, like "Spotify". Used as the link label.
+	Name() string
+
+	// NowPlaying returns the playing item, or nil if nothing is playing.
+	NowPlaying(ctx context.Context) (*NowPlaying, error)
+}
+
+// NowPlayingWatcher is a NowPlayingSource which pushes change notifications,
+// so the player doesn't wait for the next tick.
+type NowPlayingWatcher interface {
+	// Watch sends to changes when the playing item may have changed.
+	// It blocks until ctx is done, or the watch fails.
+	Watch(ctx context.Context, changes chan<- struct{}) error
+}
+
+// NowPlaying is a playing item, independent from the source.
+type NowPlaying struct {
+	Type NowPlayingType
+	// ID identifies the item within the source.
+	ID      string
+	Name    string
+	Artists string
+	Artist  string
+	// ArtistID identifies the artist for caching.
+	// Sources without IDs can use the artist name.
+	ArtistID string
+	// Album name. Can be empty.
+	Album      string
+	ProgressMs int
+	DurationMs int
+	// Link to the item on the source. Can be empty.
+	Link     string
+	CoverURL *string
+	Playing  bool
+
+	// Episode only.
+	ShowName    string
+	Publisher   string
+	Description string
+
+	// Where the item is played from. Can be nil.
+	Context *PlaybackContext
+	// Device can be nil.
+	Device  *PlaybackDevice
+	Shuffle bool
+	// Repeat is "off", "track" or "context". Empty if unknown.
+	Repeat string
+}
+
+// PlaybackContext is where the item is played from.
+type PlaybackContext struct {
+	// Type is one of ContextType* constants.
+	Type string
+	// Name can be empty.
+	Name string
+	Link string
+}
+
+// PlaybackDevice is the device the item is played on.
+type PlaybackDevice struct {
+	Name          string
+	VolumePercent int
+}
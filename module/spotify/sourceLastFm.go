@@ -0,0 +1,88 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/oklookat/teletrack/lastfm"
+)
+
+// Last.fm returns this image when there is no cover.
+const lastFmPlaceholderImage = "2a96cbd8b46e442fc41c2b86b821562f"
+
+// lastFmSource is a NowPlayingSource backed by Last.fm scrobbles.
+// It works with any player which scrobbles "now playing".
+// Last.fm knows nothing about the progress and pauses.
+type lastFmSource struct {
+	client   *lastfm.Client
+	username string
+}
+
+// NewLastFmSource creates a source which polls the user's recent tracks on Last.fm.
+func NewLastFmSource(client *lastfm.Client, username string) NowPlayingSource {
+	return &lastFmSource{
+		client:   client,
+		username: username,
+	}
+}
+
+func (s *lastFmSource) Name() string {
+	return "Last.fm"
+}
+
+func (s *lastFmSource) NowPlaying(ctx context.Context) (*NowPlaying, error) {
+	if s.username == "" {
+		return nil, errors.New("last.fm username is required")
+	}
+
+	limit := 1
+	extended := true
+	resp, err := s.client.UserGetRecentTracks(ctx, s.username, &limit, nil, nil, &extended, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The playing track is always the first one.
+	tracks := resp.Recenttracks.Track
+	if len(tracks) == 0 {
+		return nil, nil
+	}
+	track := tracks[0]
+	if track.Attr.Nowplaying == nil || !*track.Attr.Nowplaying {
+		return nil, nil
+	}
+	if err := track.Validate(); err != nil {
+		return nil, nil
+	}
+
+	artistID := track.Artist.Mbid
+	if artistID == "" {
+		artistID = track.Artist.Name
+	}
+
+	return &NowPlaying{
+		Type:     NowPlayingTrack,
+		ID:       track.Artist.Name + " - " + track.Name,
+		Name:     track.Name,
+		Artists:  track.Artist.Name,
+		Artist:   track.Artist.Name,
+		ArtistID: artistID,
+		Album:    track.Album.Text,
+		Link:     track.URL,
+		CoverURL: lastFmCover(track.Image),
+		Playing:  true,
+	}, nil
+}
+
+// lastFmCover returns the largest image, if it's not a placeholder.
+func lastFmCover(images []lastfm.Image) *string {
+	for i := len(images) - 1; i >= 0; i-- {
+		url := images[i].Text
+		if url == "" || strings.Contains(url, lastFmPlaceholderImage) {
+			continue
+		}
+		return &url
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package spotify
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklookat/teletrack/mpd"
+)
+
+// mpdSource is a NowPlayingSource backed by Music Player Daemon.
+// While watched, the state is refreshed only on "idle" notifications,
+// and the progress is extrapolated between them.
+type mpdSource struct {
+	addr     string
+	password string
+
+	mu        sync.Mutex
+	watching  bool
+	song      *mpd.Song
+	status    *mpd.Status
+	fetchedAt time.Time
+}
+
+// NewMpdSource creates a source which reads the MPD state.
+// addr is host:port, or a Unix socket path. password can be empty.
+func NewMpdSource(addr, password string) NowPlayingSource {
+	return &mpdSource{
+		addr:     addr,
+		password: password,
+	}
+}
+
+func (s *mpdSource) Name() string {
+	return "MPD"
+}
+
+func (s *mpdSource) NowPlaying(ctx context.Context) (*NowPlaying, error) {
+	s.mu.Lock()
+	watching := s.watching
+	s.mu.Unlock()
+
+	if !watching {
+		// Not watched (yet), so poll.
+		cl, err := mpd.Dial(ctx, s.addr, s.password)
+		if err != nil {
+			return nil, err
+		}
+		defer cl.Close()
+		if err := s.fetch(cl); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return mpdNowPlaying(s.song, s.status, time.Since(s.fetchedAt)), nil
+}
+
+// Watch implements NowPlayingWatcher.
+func (s *mpdSource) Watch(ctx context.Context, changes chan<- struct{}) error {
+	cl, err := mpd.Dial(ctx, s.addr, s.password)
+	if err != nil {
+		return err
+	}
+	defer cl.Close()
+
+	defer func() {
+		s.mu.Lock()
+		s.watching = false
+		s.mu.Unlock()
+	}()
+
+	for {
+		if err := s.fetch(cl); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.watching = true
+		s.mu.Unlock()
+
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+
+		if _, err := cl.Idle(ctx, "player", "mixer", "options"); err != nil {
+			return err
+		}
+	}
+}
+
+// fetch gets the current state.
+func (s *mpdSource) fetch(cl *mpd.Client) error {
+	status, err := cl.Status()
+	if err != nil {
+		return err
+	}
+	song, err := cl.CurrentSong()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.status = status
+	s.song = song
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// mpdNowPlaying converts MPD state. since is the time passed after the state was fetched.
+func mpdNowPlaying(song *mpd.Song, status *mpd.Status, since time.Duration) *NowPlaying {
+	if song == nil || status == nil || status.State == mpd.StateStop {
+		return nil
+	}
+
+	name := song.Title
+	if name == "" {
+		name = song.Name
+	}
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(song.File), path.Ext(song.File))
+	}
+
+	artistID := song.ArtistMBID
+	if artistID == "" {
+		artistID = song.Artist
+	}
+
+	duration := status.Duration
+	if duration == 0 {
+		duration = song.Duration
+	}
+	elapsed := status.Elapsed
+	playing := status.State == mpd.StatePlay
+	if playing {
+		elapsed += since
+	}
+	if duration > 0 && elapsed > duration {
+		elapsed = duration
+	}
+
+	repeat := "off"
+	if status.Repeat {
+		repeat = "context"
+		if status.Single {
+			repeat = "track"
+		}
+	}
+
+	np := &NowPlaying{
+		Type:       NowPlayingTrack,
+		ID:         song.ID + ":" + song.File,
+		Name:       name,
+		Artists:    song.Artist,
+		Artist:     song.Artist,
+		ArtistID:   artistID,
+		Album:      song.Album,
+		ProgressMs: int(elapsed.Milliseconds()),
+		DurationMs: int(duration.Milliseconds()),
+		Playing:    playing,
+		Shuffle:    status.Random,
+		Repeat:     repeat,
+	}
+	if status.Volume >= 0 {
+		np.Device = &PlaybackDevice{
+			Name:          "MPD",
+			VolumePercent: status.Volume,
+		}
+	}
+	return np
+}
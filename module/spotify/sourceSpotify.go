@@ -0,0 +1,78 @@
+package spotify
+
+import (
+	"context"
+
+	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/spoty"
+	spotifyapi "github.com/zmb3/spotify/v2"
+)
+
+// spotifySource is a NowPlayingSource backed by the Spotify API.
+type spotifySource struct {
+	client *spotifyapi.Client
+	market *spoty.Market
+	opts   spoty.PlayingOptions
+}
+
+// NewSpotifySource creates a source which polls the Spotify API.
+func NewSpotifySource(client *spotifyapi.Client, cfg *config.Spotify) NowPlayingSource {
+	pb := cfg.Playback
+	return &spotifySource{
+		client: client,
+		market: spoty.NewMarket(client, cfg.Market),
+		opts: spoty.PlayingOptions{
+			State:       pb.Device || pb.Volume || pb.Shuffle || pb.Repeat,
+			ContextName: pb.Context,
+		},
+	}
+}
+
+func (s *spotifySource) Name() string {
+	return "Spotify"
+}
+
+func (s *spotifySource) NowPlaying(ctx context.Context) (*NowPlaying, error) {
+	cur, err := spoty.GetCurrentPlaying(ctx, s.client, s.market, s.opts)
+	if err != nil || cur == nil {
+		return nil, err
+	}
+
+	playing := &NowPlaying{
+		Type:        NowPlayingTrack,
+		ID:          cur.ID,
+		Name:        cur.Name,
+		Artists:     cur.Artists,
+		Artist:      cur.Artist,
+		ArtistID:    cur.ArtistID,
+		Album:       cur.Album,
+		ProgressMs:  cur.ProgressMs,
+		DurationMs:  cur.DurationMs,
+		Link:        cur.Link,
+		CoverURL:    cur.CoverURL,
+		Playing:     cur.Playing,
+		ShowName:    cur.ShowName,
+		Publisher:   cur.Publisher,
+		Description: cur.Description,
+		Shuffle:     cur.Shuffle,
+		Repeat:      cur.Repeat,
+	}
+	if cur.Type == spoty.ItemTypeEpisode {
+		playing.Type = NowPlayingEpisode
+	}
+	if cur.Context != nil {
+		playing.Context = &PlaybackContext{
+			Type: cur.Context.Type,
+			Name: cur.Context.Name,
+			Link: cur.Context.Link,
+		}
+	}
+	if cur.Device != nil {
+		playing.Device = &PlaybackDevice{
+			Name:          cur.Device.Name,
+			VolumePercent: cur.Device.VolumePercent,
+		}
+	}
+
+	return playing, nil
+}
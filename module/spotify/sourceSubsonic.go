@@ -0,0 +1,181 @@
+package spotify
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/subsonic"
+)
+
+// After the song should have ended, it's considered stopped.
+// Servers keep songs in "now playing" for a while after the playback ends.
+const subsonicEndGrace = 30 * time.Second
+
+// subsonicSource is a NowPlayingSource backed by a Subsonic-compatible server.
+// The API has no progress, so it's counted from the moment the song appeared.
+type subsonicSource struct {
+	client      *subsonic.Client
+	share       bool
+	exposeCover bool
+	shares      *expirable.LRU[string, string]
+	// covers keeps the cover URL of the cover ID. A new URL has a new salt,
+	// so the post would be edited with the same cover on every poll.
+	covers *expirable.LRU[string, string]
+
+	mu        sync.Mutex
+	songID    string
+	startedAt time.Time
+	shareErr  bool
+}
+
+// NewSubsonicSource creates a source which polls the server's now playing list.
+func NewSubsonicSource(cfg *config.Subsonic) NowPlayingSource {
+	return &subsonicSource{
+		client:      subsonic.NewClient(cfg.URL, cfg.Username, cfg.Password),
+		share:       cfg.Share,
+		exposeCover: cfg.ExposeCoverArt,
+		shares:      expirable.NewLRU[string, string](50, nil, 24*time.Hour),
+		covers:      expirable.NewLRU[string, string](50, nil, 24*time.Hour),
+	}
+}
+
+func (s *subsonicSource) Name() string {
+	return "Subsonic"
+}
+
+func (s *subsonicSource) NowPlaying(ctx context.Context) (*NowPlaying, error) {
+	entries, err := s.client.GetNowPlaying(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Server lists all users, take the latest song of ours.
+	var own []subsonic.NowPlayingEntry
+	for _, entry := range entries {
+		if entry.Username == s.client.Username {
+			own = append(own, entry)
+		}
+	}
+	if len(own) == 0 {
+		s.resetProgress()
+		return nil, nil
+	}
+	sort.SliceStable(own, func(i, j int) bool {
+		return own[i].MinutesAgo < own[j].MinutesAgo
+	})
+	entry := own[0]
+
+	duration := time.Duration(entry.Duration) * time.Second
+	progress := s.progress(entry)
+	if duration > 0 && progress > duration+subsonicEndGrace {
+		return nil, nil
+	}
+	if duration > 0 && progress > duration {
+		progress = duration
+	}
+
+	artistID := entry.ArtistID
+	if artistID == "" {
+		artistID = entry.Artist
+	}
+
+	playing := &NowPlaying{
+		Type:       NowPlayingTrack,
+		ID:         entry.ID,
+		Name:       entry.Title,
+		Artists:    entry.Artist,
+		Artist:     entry.Artist,
+		ArtistID:   artistID,
+		Album:      entry.Album,
+		ProgressMs: int(progress.Milliseconds()),
+		DurationMs: int(duration.Milliseconds()),
+		Link:       s.shareURL(ctx, entry),
+		Playing:    true,
+	}
+	if coverURL := s.coverURL(entry); coverURL != "" {
+		playing.CoverURL = &coverURL
+	}
+	if entry.PlayerName != "" {
+		playing.Device = &PlaybackDevice{
+			Name: entry.PlayerName,
+		}
+	}
+
+	return playing, nil
+}
+
+// progress estimates the progress of the entry.
+func (s *subsonicSource) progress(entry subsonic.NowPlayingEntry) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	serverStart := time.Now().Add(-time.Duration(entry.MinutesAgo) * time.Minute)
+	if s.songID != entry.ID {
+		s.songID = entry.ID
+		s.startedAt = serverStart
+	}
+
+	progress := time.Since(s.startedAt)
+	duration := time.Duration(entry.Duration) * time.Second
+	// minutesAgo is rounded down, so the server start is less than a minute after the real one.
+	// If it's later, the song is played again (repeat one, or queued twice).
+	if duration > 0 && progress > duration && serverStart.Sub(s.startedAt) >= time.Minute {
+		s.startedAt = serverStart
+		progress = time.Since(s.startedAt)
+	}
+	return progress
+}
+
+func (s *subsonicSource) resetProgress() {
+	s.mu.Lock()
+	s.songID = ""
+	s.mu.Unlock()
+}
+
+// coverURL returns the cover URL of the entry, built once per cover.
+func (s *subsonicSource) coverURL(entry subsonic.NowPlayingEntry) string {
+	if !s.exposeCover || entry.CoverArt == "" {
+		return ""
+	}
+	if coverURL, ok := s.covers.Get(entry.CoverArt); ok {
+		return coverURL
+	}
+	coverURL, err := s.client.CoverArtURL(entry.CoverArt, 600)
+	if err != nil {
+		return ""
+	}
+	s.covers.Add(entry.CoverArt, coverURL)
+	return coverURL
+}
+
+// shareURL returns a public link to the song, creating a share once per song.
+func (s *subsonicSource) shareURL(ctx context.Context, entry subsonic.NowPlayingEntry) string {
+	if !s.share {
+		return ""
+	}
+	if link, ok := s.shares.Get(entry.ID); ok {
+		return link
+	}
+
+	share, err := s.client.CreateShare(ctx, entry.ID, entry.Artist+" - "+entry.Title)
+	if err != nil {
+		s.mu.Lock()
+		if !s.shareErr {
+			// Most likely sharing is disabled on the server. Don't spam.
+			s.shareErr = true
+			slog.Warn("failed to create Subsonic share", "err", err)
+		}
+		s.mu.Unlock()
+		// Don't try again for this song.
+		s.shares.Add(entry.ID, "")
+		return ""
+	}
+
+	s.shares.Add(entry.ID, share.URL)
+	return share.URL
+}
@@ -0,0 +1,57 @@
+package spotify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/subsonic"
+)
+
+func TestSubsonicProgressReplay(t *testing.T) {
+	source := &subsonicSource{}
+	entry := subsonic.NowPlayingEntry{ID: "1", Duration: 180, MinutesAgo: 1}
+
+	if progress := source.progress(entry); progress < time.Minute || progress > time.Minute+time.Second {
+		t.Fatalf("unexpected progress %s", progress)
+	}
+
+	// Ended, but still listed.
+	source.startedAt = time.Now().Add(-200 * time.Second)
+	entry.MinutesAgo = 3
+	if progress := source.progress(entry); progress < 200*time.Second {
+		t.Fatalf("unexpected progress %s", progress)
+	}
+
+	// Played again.
+	entry.MinutesAgo = 0
+	if progress := source.progress(entry); progress > time.Second {
+		t.Fatalf("expected restarted progress, got %s", progress)
+	}
+}
+
+func TestSubsonicCoverURLStable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"subsonic-response":{"status":"ok","version":"1.16.1","nowPlaying":{"entry":[
+			{"id":"1","title":"Song","artist":"Artist","coverArt":"al-1","duration":200,"username":"user","minutesAgo":0}
+		]}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	source := NewSubsonicSource(&config.Subsonic{URL: srv.URL, Username: "user", Password: "secret", ExposeCoverArt: true})
+	ctx := context.Background()
+	first, err := source.NowPlaying(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := source.NowPlaying(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.CoverURL == nil || second.CoverURL == nil || *first.CoverURL != *second.CoverURL {
+		t.Fatalf("expected the same cover URL, got %v and %v", first.CoverURL, second.CoverURL)
+	}
+}
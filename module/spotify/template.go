@@ -0,0 +1,371 @@
+package spotify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode/utf8"
+
+	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/locale"
+	"github.com/oklookat/teletrack/shared"
+)
+
+// Telegram limit of the message text, after entities parsing.
+const maxMessageLength = 4096
+
+// Telegram limit of the photo caption, after entities parsing.
+const maxCaptionLength = 1024
+
+// escapeFuncName is appended to every template action, so values are escaped automatically.
+const escapeFuncName = "_escape"
+
+const defaultPlayingTemplate = `{{.Time}}
+
+{{if .Playing}}▶️{{else}}⏸️{{end}} {{if .Episode}}🎙️ {{end}}{{code .FullName}}
+{{if .Item.Publisher}}👤 {{.Item.Publisher}}
+{{end}}
+{{if .Progress.Known}}{{.Progress.Elapsed}} {{.Progress.Bar}} {{.Progress.Duration}}
+
+{{end}}{{if .Playback}}{{.Playback}}
+
+{{end}}{{if .Plays}}{{tr "lastfm.plays" .Plays}}
+
+{{end}}{{if .TagsLine}}{{.TagsLine}}
+
+{{end}}{{if .Bio}}{{.Bio}}
+
+{{end}}{{if .AlbumBlurb}}{{.AlbumBlurb}}
+
+{{end}}{{if .Description}}{{.Description}}
+
+{{end}}{{if .Links}}{{range $i, $l := .Links}}{{if $i}}
+{{end}}🔗 {{link $l.Label $l.URL}}{{end}}
+
+{{end}}{{.Emoji}}
+{{link (tr "poweredBy") "https://github.com/oklookat/teletrack"}}`
+
+const defaultIdleTemplate = `{{.Time}}{{if .Text}}
+
+{{.Text}}{{end}}{{range .Groups}}{{if .Links}}
+
+{{range $i, $l := .Links}}{{if $i}}
+{{end}}{{link $l.Label $l.URL}}{{end}}{{end}}{{end}}`
+
+// Markdown is a MarkdownV2 string, which is not escaped in templates.
+type Markdown string
+
+// PlayingData is the data of the playing post template.
+// All strings are plain text, except Markdown.
+type PlayingData struct {
+	// Time is the current time, formatted.
+	Time string
+	// Playing is false if paused.
+	Playing bool
+	// Episode is true for podcast episodes.
+	Episode bool
+	// Source is the source name, like "Spotify".
+	Source string
+	// Item is the playing item as is.
+	Item *NowPlaying
+	// FullName is "Artist - Track", or "Show - Episode".
+	// The artist name is corrected by Last.fm, like "Guns N' Roses" for "guns and roses".
+	FullName string
+	Progress ProgressData
+	// Playback is the formatted line like "from playlist X on device Y 🔀". Can be empty.
+	Playback Markdown
+	// Bio is the artist bio from Last.fm. Can be empty.
+	Bio string
+	// Tags are the top tags of the artist from Last.fm. Can be empty.
+	Tags []string
+	// Similar are the similar artists from Last.fm. Can be empty.
+	Similar []LinkData
+	// TagsLine is the formatted line like "Tags: #indie #shoegaze · Similar: X, Y". Can be empty.
+	TagsLine Markdown
+	// Plays is how many times the Last.fm user played the track. 0 if unknown.
+	Plays int
+	// AlbumBlurb is the album wiki summary from Last.fm. Can be empty.
+	AlbumBlurb string
+	// Description is the episode description. Can be empty.
+	Description string
+	// Links are the item on the source, and the artist on Last.fm. Can be empty.
+	Links []LinkData
+	// Emoji is random emoji, chosen once per item.
+	Emoji string
+}
+
+// ProgressData is the playback progress.
+type ProgressData struct {
+	// Known is false, if the source knows nothing about the progress.
+	Known bool
+	// Elapsed is like "01:23".
+	Elapsed string
+	// Duration is like "03:45".
+	Duration string
+	// Bar is like "[███░░░░░░░░░]".
+	Bar        string
+	ProgressMs int
+	DurationMs int
+}
+
+// IdleData is the data of the idle post template.
+type IdleData struct {
+	// Time is the current time, formatted.
+	Time   string
+	Text   string
+	Groups []LinkGroupData
+}
+
+// LinkGroupData is a group of links.
+type LinkGroupData struct {
+	Links []LinkData
+}
+
+// LinkData is a link. URL can be empty.
+type LinkData struct {
+	Label string
+	URL   string
+}
+
+// Templates are the post templates.
+type Templates struct {
+	playing *template.Template
+	idle    *template.Template
+}
+
+var _templateFuncs = template.FuncMap{
+	escapeFuncName: func(v any) Markdown {
+		if md, ok := v.(Markdown); ok {
+			return md
+		}
+		return Markdown(shared.TgText(fmt.Sprint(v)))
+	},
+	// tr returns the message of the current locale.
+	"tr": func(key string, args ...any) string {
+		return locale.T(locale.Key(key), args...)
+	},
+	// raw inserts the string without escaping.
+	"raw": func(s string) Markdown {
+		return Markdown(s)
+	},
+	// link builds a link, or escaped text if url is empty.
+	"link": func(label, url string) Markdown {
+		if url == "" {
+			return Markdown(shared.TgText(label))
+		}
+		return Markdown(shared.TgLink(label, url))
+	},
+	"code": func(s string) Markdown {
+		return Markdown("`" + shared.SanitizeCodeSpan(s) + "`")
+	},
+	"bold": func(s string) Markdown {
+		return Markdown("*" + shared.TgText(s) + "*")
+	},
+	"italic": func(s string) Markdown {
+		return Markdown("_" + shared.TgText(s) + "_")
+	},
+	"spoiler": func(s string) Markdown {
+		return Markdown("||" + shared.TgText(s) + "||")
+	},
+}
+
+// LoadTemplates loads the post templates from config, or the default ones.
+// Templates are validated with sample data.
+func LoadTemplates(cfg *config.Templates) (*Templates, error) {
+	if cfg == nil {
+		cfg = &config.Templates{}
+	}
+
+	playingText, err := templateText(cfg.Playing, cfg.PlayingFile, defaultPlayingTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("playing template: %w", err)
+	}
+	idleText, err := templateText(cfg.Idle, cfg.IdleFile, defaultIdleTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("idle template: %w", err)
+	}
+
+	tmpls := &Templates{}
+	if tmpls.playing, err = parseTemplate("playing", playingText); err != nil {
+		return nil, err
+	}
+	if tmpls.idle, err = parseTemplate("idle", idleText); err != nil {
+		return nil, err
+	}
+
+	if err := tmpls.validate(); err != nil {
+		return nil, err
+	}
+	return tmpls, nil
+}
+
+// Playing renders the playing post.
+func (t *Templates) Playing(data *PlayingData) (string, error) {
+	return execTemplate(t.playing, data)
+}
+
+// Idle renders the idle post.
+func (t *Templates) Idle(data *IdleData) (string, error) {
+	return execTemplate(t.idle, data)
+}
+
+// validate renders templates with sample data, and checks the result.
+func (t *Templates) validate() error {
+	samples := []*PlayingData{samplePlayingData(NowPlayingTrack), samplePlayingData(NowPlayingEpisode)}
+	for _, sample := range samples {
+		text, err := t.Playing(sample)
+		if err != nil {
+			return err
+		}
+		if err := validateMessage(text); err != nil {
+			return fmt.Errorf("playing template (%s): %w", sample.Item.Type, err)
+		}
+	}
+
+	text, err := t.Idle(sampleIdleData())
+	if err != nil {
+		return err
+	}
+	if err := validateMessage(text); err != nil {
+		return fmt.Errorf("idle template: %w", err)
+	}
+	return nil
+}
+
+func validateMessage(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("empty message")
+	}
+	if utf8.RuneCountInString(text) > maxMessageLength {
+		return fmt.Errorf("message is longer than %d characters", maxMessageLength)
+	}
+	return shared.ValidateMarkdownV2(text)
+}
+
+func templateText(inline, file, def string) (string, error) {
+	if len(inline) > 0 {
+		return inline, nil
+	}
+	if len(file) > 0 {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return def, nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).
+		Funcs(_templateFuncs).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			autoEscape(t.Tree.Root)
+		}
+	}
+	return tmpl, nil
+}
+
+func execTemplate(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// autoEscape appends the escape function to every action which prints something.
+func autoEscape(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			autoEscape(child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			// Variable declaration prints nothing.
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(escapeFuncName).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		autoEscape(n.List)
+		autoEscape(n.ElseList)
+	case *parse.RangeNode:
+		autoEscape(n.List)
+		autoEscape(n.ElseList)
+	case *parse.WithNode:
+		autoEscape(n.List)
+		autoEscape(n.ElseList)
+	}
+}
+
+func samplePlayingData(typ NowPlayingType) *PlayingData {
+	cover := "https://example.com/cover.jpg"
+	item := &NowPlaying{
+		Type:       typ,
+		ID:         "1",
+		Name:       "Track (Remix) [2024]",
+		Artists:    "Artist, Other_Artist",
+		Artist:     "Artist",
+		ArtistID:   "1",
+		Album:      "Album",
+		ProgressMs: 83000,
+		DurationMs: 225000,
+		Link:       "https://example.com/track/1",
+		CoverURL:   &cover,
+		Playing:    true,
+		Context:    &PlaybackContext{Type: ContextTypePlaylist, Name: "Playlist!", Link: "https://example.com/playlist/1"},
+		Device:     &PlaybackDevice{Name: "Phone", VolumePercent: 50},
+		Repeat:     "off",
+	}
+	if typ == NowPlayingEpisode {
+		item.ShowName = "Show #1"
+		item.Publisher = "Publisher & Co."
+		item.Description = "Episode description."
+	}
+
+	data := newPlayingData(item, "Sample", &cachedArtistInfo{
+		Bio:       "Artist is a *band* from U.K. (formed in 2000).",
+		LastFmURL: "https://www.last.fm/music/Artist",
+		Tags:      []string{"indie", "post-rock"},
+		Similar:   []LinkData{{Label: "Other_Artist", URL: "https://www.last.fm/music/Other_Artist"}},
+	}, &cachedTrackInfo{
+		FullName:    item.Artist + " - " + item.Name,
+		Link:        item.Link,
+		Emoji:       "(╯°□°)╯︵ ┻━┻",
+		Plays:       42,
+		AlbumBlurb:  "Album is the 2nd album of Artist (2024).",
+		Publisher:   item.Publisher,
+		Description: item.Description,
+	}, config.SpotifyPlayback{Context: true, Device: true, Volume: true, Shuffle: true, Repeat: true})
+	data.Time = "12:34:56 01.02.2025 (MSK)"
+	return data
+}
+
+func sampleIdleData() *IdleData {
+	return &IdleData{
+		Time: "12:34:56 01.02.2025 (MSK)",
+		Text: "Nothing playing. Come back later!",
+		Groups: []LinkGroupData{
+			{Links: []LinkData{{Label: "✉️ name@example.com"}}},
+			{Links: []LinkData{{Label: "💻 GitHub (me)", URL: "https://github.com/example"}}},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package spotify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oklookat/teletrack/config"
+)
+
+func TestDefaultTemplates(t *testing.T) {
+	tmpls, err := LoadTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := tmpls.Playing(samplePlayingData(NowPlayingTrack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "12:34:56 01\\.02\\.2025 \\(MSK\\)\n\n" +
+		"▶️ `Artist - Track (Remix) [2024]`\n\n" +
+		"01:23 \\[████░░░░░░░░\\] 03:45\n\n"
+	if !strings.HasPrefix(text, expected) {
+		t.Fatalf("unexpected message:\n%s", text)
+	}
+	if !strings.Contains(text, "🎧 Your plays: 42\n\n") {
+		t.Fatalf("plays not found:\n%s", text)
+	}
+	if !strings.Contains(text, "Tags: \\#indie \\#post\\_rock · Similar: [Other\\_Artist](https://www.last.fm/music/Other_Artist)\n\n") {
+		t.Fatalf("tags not found:\n%s", text)
+	}
+	if !strings.Contains(text, "🔗 [Sample](https://example.com/track/1)\n🔗 [Last\\.fm](https://www.last.fm/music/Artist)\n\n") {
+		t.Fatalf("links not found:\n%s", text)
+	}
+}
+
+func TestTemplateAutoEscape(t *testing.T) {
+	tmpls, err := LoadTemplates(&config.Templates{
+		Playing: `{{$name := .FullName}}{{$name}} {{bold .Item.Artist}} {{raw "\\!"}}{{range .Links}} {{.Label}}{{end}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := samplePlayingData(NowPlayingTrack)
+	data.FullName = "a.b"
+	data.Item.Artist = "c-d"
+	data.Links = []LinkData{{Label: "e!"}}
+	text, err := tmpls.Playing(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `a\.b *c\-d* \! e\!`; text != expected {
+		t.Fatalf("expected %q, got %q", expected, text)
+	}
+}
+
+func TestTemplateValidation(t *testing.T) {
+	broken := []*config.Templates{
+		{Playing: `{{.Unknown}}`},
+		{Playing: `{{if .Playing}}`},
+		{Playing: `{{raw "1.0"}}`},
+		{Idle: `*{{.Time}}`},
+		{Idle: `  `},
+	}
+	for _, cfg := range broken {
+		if _, err := LoadTemplates(cfg); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package spotify
 
 import (
 	"fmt"
+	"html"
+	"regexp"
+	"strings"
 )
 
 // wrapErr — small helper to keep error messages consistent
@@ -11,3 +14,34 @@ func wrapErr(ctx string, err error) error {
 	}
 	return fmt.Errorf("%s: %w", ctx, err)
 }
+
+var (
+	_htmlTagRe  = regexp.MustCompile(`<[^>]*>`)
+	_readMoreRe = regexp.MustCompile(`(?i)\s*read\s+more\s+on\s+last\.?fm\.?`)
+	// Tags are replaced with spaces, which must not stay before punctuation.
+	_spacePunctRe = regexp.MustCompile(`\s+([.,;:!?])`)
+)
+
+// plainText strips HTML and the "Read more on Last.fm" link, collapses whitespace,
+// and truncates the text to limit runes at a word boundary.
+func plainText(s string, limit int) string {
+	s = _htmlTagRe.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	s = _readMoreRe.ReplaceAllString(s, "")
+	s = strings.Join(strings.Fields(s), " ")
+	s = _spacePunctRe.ReplaceAllString(s, "$1")
+	return truncateRunes(s, limit)
+}
+
+// truncateRunes cuts s to limit runes with "…", at a word boundary if there is one.
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if limit <= 0 || len(runes) <= limit {
+		return s
+	}
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:-—") + "…"
+}
@@ -0,0 +1,31 @@
+package spotify
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"<p>Dr. Smith talks to <b>guests</b>.</p>\n\nNew episode &amp; more.", 100, "Dr. Smith talks to guests. New episode & more."},
+		{`The album. <a href="https://www.last.fm/music/A/B">Read more on Last.fm</a>`, 100, "The album."},
+		{"Text with &lt;html&gt; tags", 100, "Text with <html> tags"},
+		{"Блэйн Мьюз, более известна как Shygirl", 20, "Блэйн Мьюз, более…"},
+		{"Word", 3, "Wo…"},
+	}
+	for _, tt := range tests {
+		if got := plainText(tt.in, tt.limit); got != tt.want {
+			t.Errorf("plainText(%q, %d) = %q, want %q", tt.in, tt.limit, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("слово ", 100)
+	if got := plainText(long, 50); utf8.RuneCountInString(got) > 50 || !utf8.ValidString(got) {
+		t.Fatalf("unexpected truncation %q", got)
+	}
+}
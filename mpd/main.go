@@ -0,0 +1,236 @@
+package mpd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const commandTimeout = 10 * time.Second
+
+// Client is a Music Player Daemon client speaking the text protocol.
+// It's not safe for concurrent commands, except cancelling Idle.
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	writeMu sync.Mutex
+
+	// Version is the protocol version from the server greeting.
+	Version string
+}
+
+// Attr is a "key: value" response line.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// Attrs is a command response.
+type Attrs []Attr
+
+// Get returns the first value of key, or empty string.
+func (a Attrs) Get(key string) string {
+	for _, attr := range a {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// Error is an ACK returned by the server.
+type Error struct {
+	Code    int
+	Command string
+	Message string
+}
+
+// Error implements the error interface for Error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("mpd: %s (command: %s, code: %d)", e.Message, e.Command, e.Code)
+}
+
+// Dial connects to MPD. addr is host:port, or a Unix socket path.
+// password can be empty.
+func Dial(ctx context.Context, addr, password string) (*Client, error) {
+	network := "tcp"
+	if strings.HasPrefix(addr, "/") || strings.HasPrefix(addr, "@") {
+		network = "unix"
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+
+	conn.SetReadDeadline(time.Now().Add(commandTimeout))
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read greeting: %w", err)
+	}
+	version, ok := strings.CutPrefix(greeting, "OK MPD ")
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected greeting: %s", greeting)
+	}
+	c.Version = version
+	conn.SetReadDeadline(time.Time{})
+
+	if password != "" {
+		if _, err := c.Command("password", password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.writeLine("close")
+	return c.conn.Close()
+}
+
+// Command runs a command and returns its response.
+func (c *Client) Command(name string, args ...string) (Attrs, error) {
+	c.conn.SetDeadline(time.Now().Add(commandTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := c.writeLine(formatCommand(name, args...)); err != nil {
+		return nil, err
+	}
+	return c.readResponse()
+}
+
+// Idle waits until one of subsystems changes (any, if empty), and returns the changed ones.
+// When ctx is done, the wait is cancelled with "noidle", and the answer is awaited
+// no longer than a command.
+func (c *Client) Idle(ctx context.Context, subsystems ...string) ([]string, error) {
+	if err := c.writeLine(formatCommand("idle", subsystems...)); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	cancelled := make(chan struct{})
+	go func() {
+		defer close(cancelled)
+		select {
+		case <-ctx.Done():
+			// The deadline ends the read, even if the server never answers.
+			c.conn.SetDeadline(time.Now().Add(commandTimeout))
+			c.writeLine("noidle")
+		case <-done:
+		}
+	}()
+
+	attrs, err := c.readResponse()
+	close(done)
+	<-cancelled
+	c.conn.SetDeadline(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var changed []string
+	for _, attr := range attrs {
+		if attr.Key == "changed" {
+			changed = append(changed, attr.Value)
+		}
+	}
+	return changed, nil
+}
+
+func (c *Client) writeLine(line string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write([]byte(line + "\n"))
+	return err
+}
+
+func (c *Client) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+func (c *Client) readResponse() (Attrs, error) {
+	var attrs Attrs
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "OK" {
+			return attrs, nil
+		}
+		if strings.HasPrefix(line, "ACK ") {
+			return nil, parseAck(line)
+		}
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, fmt.Errorf("malformed response line: %s", line)
+		}
+		attrs = append(attrs, Attr{Key: key, Value: value})
+	}
+}
+
+// parseAck parses "ACK [error@command_listNum] {current_command} message_text".
+func parseAck(line string) error {
+	mpdErr := &Error{Message: line}
+
+	rest := strings.TrimPrefix(line, "ACK ")
+	if !strings.HasPrefix(rest, "[") {
+		return mpdErr
+	}
+	codePart, rest, ok := strings.Cut(rest[1:], "] ")
+	if !ok {
+		return mpdErr
+	}
+	code, _, _ := strings.Cut(codePart, "@")
+	fmt.Sscanf(code, "%d", &mpdErr.Code)
+
+	if strings.HasPrefix(rest, "{") {
+		cmd, msg, ok := strings.Cut(rest[1:], "} ")
+		if ok {
+			mpdErr.Command = cmd
+			rest = msg
+		}
+	}
+	mpdErr.Message = rest
+	return mpdErr
+}
+
+// formatCommand quotes arguments, if needed.
+func formatCommand(name string, args ...string) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, arg := range args {
+		sb.WriteByte(' ')
+		if arg != "" && !strings.ContainsAny(arg, " \t\"\\'") {
+			sb.WriteString(arg)
+			continue
+		}
+		sb.WriteByte('"')
+		sb.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg))
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
@@ -0,0 +1,177 @@
+package mpd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer is a minimal MPD speaking the text protocol.
+type fakeServer struct {
+	ln      net.Listener
+	changed chan string
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &fakeServer{
+		ln:      ln,
+		changed: make(chan string, 1),
+	}
+	t.Cleanup(func() { ln.Close() })
+	go srv.serve()
+	return srv
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	w := func(lines ...string) {
+		conn.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	}
+	w("OK MPD 0.23.5")
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		r := bufio.NewScanner(conn)
+		for r.Scan() {
+			lines <- r.Text()
+		}
+	}()
+
+	for line := range lines {
+		switch {
+		case line == `password "bad pass"`:
+			w("ACK [3@0] {password} incorrect password")
+		case line == "password secret":
+			w("OK")
+		case line == "status":
+			w("volume: 40", "repeat: 1", "random: 0", "single: 0", "state: play",
+				"songid: 7", "elapsed: 12.500", "duration: 200.000", "OK")
+		case line == "currentsong":
+			w("file: music/song.flac", "Title: Song", "Artist: Artist", "Album: Album",
+				"Time: 200", "duration: 200.000", "Id: 7", "OK")
+		case strings.HasPrefix(line, "idle"):
+			select {
+			case sub := <-s.changed:
+				w("changed: "+sub, "OK")
+			case next, ok := <-lines:
+				if !ok {
+					return
+				}
+				if next == "noidle" {
+					w("OK")
+				}
+			}
+		case line == "close":
+			return
+		default:
+			w("ACK [5@0] {} unknown command")
+		}
+	}
+}
+
+func dial(t *testing.T, srv *fakeServer, password string) *Client {
+	cl, err := Dial(context.Background(), srv.ln.Addr().String(), password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cl.Close() })
+	return cl
+}
+
+func TestDial(t *testing.T) {
+	srv := newFakeServer(t)
+
+	cl := dial(t, srv, "secret")
+	if cl.Version != "0.23.5" {
+		t.Fatalf("unexpected version %q", cl.Version)
+	}
+
+	_, err := Dial(context.Background(), srv.ln.Addr().String(), "bad pass")
+	var mpdErr *Error
+	if !errors.As(err, &mpdErr) {
+		t.Fatalf("expected ACK error, got %v", err)
+	}
+	if mpdErr.Code != 3 || mpdErr.Command != "password" || mpdErr.Message != "incorrect password" {
+		t.Fatalf("unexpected error %+v", mpdErr)
+	}
+}
+
+func TestStatusAndCurrentSong(t *testing.T) {
+	cl := dial(t, newFakeServer(t), "")
+
+	status, err := cl.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != StatePlay || status.Volume != 40 || !status.Repeat || status.Random {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.Elapsed != 12500*time.Millisecond || status.Duration != 200*time.Second {
+		t.Fatalf("unexpected time %+v", status)
+	}
+
+	song, err := cl.CurrentSong()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if song.ID != "7" || song.Title != "Song" || song.Artist != "Artist" || song.Duration != 200*time.Second {
+		t.Fatalf("unexpected song %+v", song)
+	}
+}
+
+func TestIdle(t *testing.T) {
+	srv := newFakeServer(t)
+	cl := dial(t, srv, "")
+
+	srv.changed <- "player"
+	changed, err := cl.Idle(context.Background(), "player")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "player" {
+		t.Fatalf("unexpected changes %v", changed)
+	}
+
+	// Cancelled idle leaves the connection usable.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := cl.Idle(ctx, "player"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline, got %v", err)
+	}
+	if _, err := cl.Status(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	tests := map[string]string{
+		formatCommand("status"):                  "status",
+		formatCommand("idle", "player", "mixer"): "idle player mixer",
+		formatCommand("find", `a "b"`):           `find "a \"b\""`,
+		formatCommand("password", ""):            `password ""`,
+	}
+	for got, expected := range tests {
+		if got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+}
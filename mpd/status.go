@@ -0,0 +1,113 @@
+package mpd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Player states.
+const (
+	StatePlay  = "play"
+	StatePause = "pause"
+	StateStop  = "stop"
+)
+
+// Status is the response of the "status" command.
+type Status struct {
+	// State is one of State* constants.
+	State string
+	// Volume is 0-100, or -1 if there is no mixer.
+	Volume  int
+	Repeat  bool
+	Random  bool
+	Single  bool
+	Elapsed time.Duration
+	// Duration of the current song. Zero for streams.
+	Duration time.Duration
+	SongID   string
+}
+
+// Song is the response of the "currentsong" command.
+type Song struct {
+	ID     string
+	File   string
+	Title  string
+	Artist string
+	Album  string
+	// Name is the stream name, if the song is a stream.
+	Name     string
+	Duration time.Duration
+
+	// ArtistMBID is the MusicBrainz artist ID. Can be empty.
+	ArtistMBID string
+}
+
+// Status returns the player status.
+func (c *Client) Status() (*Status, error) {
+	attrs, err := c.Command("status")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		State:    attrs.Get("state"),
+		Volume:   -1,
+		Repeat:   attrs.Get("repeat") == "1",
+		Random:   attrs.Get("random") == "1",
+		Single:   attrs.Get("single") == "1" || attrs.Get("single") == "oneshot",
+		Elapsed:  parseSeconds(attrs.Get("elapsed")),
+		Duration: parseSeconds(attrs.Get("duration")),
+		SongID:   attrs.Get("songid"),
+	}
+	if vol, err := strconv.Atoi(attrs.Get("volume")); err == nil {
+		status.Volume = vol
+	}
+
+	// Old servers have only "time: elapsed:total".
+	if elapsed, total, ok := strings.Cut(attrs.Get("time"), ":"); ok {
+		if status.Elapsed == 0 {
+			status.Elapsed = parseSeconds(elapsed)
+		}
+		if status.Duration == 0 {
+			status.Duration = parseSeconds(total)
+		}
+	}
+
+	return status, nil
+}
+
+// CurrentSong returns the current song, or nil if there is none.
+func (c *Client) CurrentSong() (*Song, error) {
+	attrs, err := c.Command("currentsong")
+	if err != nil {
+		return nil, err
+	}
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+
+	song := &Song{
+		ID:         attrs.Get("Id"),
+		File:       attrs.Get("file"),
+		Title:      attrs.Get("Title"),
+		Artist:     attrs.Get("Artist"),
+		Album:      attrs.Get("Album"),
+		Name:       attrs.Get("Name"),
+		Duration:   parseSeconds(attrs.Get("duration")),
+		ArtistMBID: attrs.Get("MUSICBRAINZ_ARTISTID"),
+	}
+	if song.Duration == 0 {
+		song.Duration = parseSeconds(attrs.Get("Time"))
+	}
+	return song, nil
+}
+
+// parseSeconds parses seconds like "12.345".
+func parseSeconds(s string) time.Duration {
+	sec, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(sec * float64(time.Second))
+}
@@ -103,32 +103,6 @@ func TgLink(description, link string) string {
 	return fmt.Sprintf("[%s](%s)", bot.EscapeMarkdownUnescaped(description), link)
 }
 
-// TimeToRu formats time in Moscow timezone (MSK). Falls back to local time if loading the location fails.
-func TimeToRu(t time.Time) string {
-	loc, err := time.LoadLocation("Europe/Moscow")
-	if err != nil {
-		loc = time.Local
-	}
-	tInLocation := t.In(loc)
-	return tInLocation.Format("15:04 02.01.2006") + fmt.Sprintf(" (%s)", GetTimeZone())
-}
-
-// TimeToRuWithSeconds formats time in Moscow timezone with seconds. Falls back to local time if loading the location fails.
-func TimeToRuWithSeconds(t time.Time) string {
-	loc, err := time.LoadLocation("Europe/Moscow")
-	if err != nil {
-		loc = time.Local
-	}
-	tInLocation := t.In(loc)
-	return tInLocation.Format("15:04:05 02.01.2006") + " (MSK)"
-}
-
-// GetTimeZone returns the current system timezone abbreviation.
-func GetTimeZone() string {
-	zone, _ := time.Now().Zone()
-	return zone
-}
-
 // EscapeMarkdownV2 escapes characters that must be escaped for Telegram MarkdownV2.
 // We precompiled the regex above for performance.
 func EscapeMarkdownV2(input string) string {
@@ -0,0 +1,118 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateMarkdownV2 does a light check of Telegram MarkdownV2 text:
+// reserved characters must be escaped, entities and links must be closed.
+// It catches most of the mistakes which lead to "can't parse entities" errors.
+func ValidateMarkdownV2(text string) error {
+	runes := []rune(text)
+
+	// Open formatting entities, like "*" or "||".
+	var open []string
+	toggle := func(marker string) {
+		if len(open) > 0 && open[len(open)-1] == marker {
+			open = open[:len(open)-1]
+			return
+		}
+		for _, m := range open {
+			if m == marker {
+				// Closed out of order, like "*_text*_".
+				open = append(open, "!"+marker)
+				return
+			}
+		}
+		open = append(open, marker)
+	}
+
+	inLinkText := false
+	lineStart := true
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		atLineStart := lineStart
+		lineStart = r == '\n'
+
+		switch r {
+		case '\\':
+			// Escaped character.
+			i++
+		case '`':
+			// Code span or pre block: everything is literal until the closing one.
+			marker := "`"
+			if strings.HasPrefix(string(runes[i:]), "```") {
+				marker = "```"
+			}
+			end := indexUnescaped(runes, i+len(marker), marker)
+			if end < 0 {
+				return fmt.Errorf("unclosed code at %d", i)
+			}
+			i = end + len(marker) - 1
+		case '*', '~':
+			toggle(string(r))
+		case '_':
+			if i+1 < len(runes) && runes[i+1] == '_' {
+				toggle("__")
+				i++
+				continue
+			}
+			toggle("_")
+		case '|':
+			if i+1 < len(runes) && runes[i+1] == '|' {
+				toggle("||")
+				i++
+				continue
+			}
+			return fmt.Errorf("unescaped %q at %d", r, i)
+		case '[':
+			if inLinkText {
+				return fmt.Errorf("nested link at %d", i)
+			}
+			inLinkText = true
+		case ']':
+			if !inLinkText {
+				return fmt.Errorf("unescaped %q at %d", r, i)
+			}
+			inLinkText = false
+			if i+1 >= len(runes) || runes[i+1] != '(' {
+				return fmt.Errorf("link without URL at %d", i)
+			}
+			end := indexUnescaped(runes, i+2, ")")
+			if end < 0 {
+				return fmt.Errorf("unclosed link URL at %d", i)
+			}
+			i = end
+		case '>':
+			if !atLineStart {
+				return fmt.Errorf("unescaped %q at %d", r, i)
+			}
+		case '(', ')', '#', '+', '-', '=', '{', '}', '.', '!':
+			return fmt.Errorf("unescaped %q at %d", r, i)
+		}
+	}
+
+	if inLinkText {
+		return fmt.Errorf("unclosed link")
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("unclosed or mismatched %q", strings.TrimPrefix(open[len(open)-1], "!"))
+	}
+	return nil
+}
+
+// indexUnescaped returns the index of the first unescaped marker after from, or -1.
+func indexUnescaped(runes []rune, from int, marker string) int {
+	m := []rune(marker)
+	for i := from; i < len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+		if i+len(m) <= len(runes) && string(runes[i:i+len(m)]) == marker {
+			return i
+		}
+	}
+	return -1
+}
@@ -3,31 +3,90 @@ package spoty
 import (
 	"context"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/zmb3/spotify/v2"
 )
 
+// ItemType is the type of the playing item.
+type ItemType string
+
+const (
+	ItemTypeTrack ItemType = "track"
+	// ItemTypeEpisode is a podcast episode. Audiobook chapters are reported as episodes too.
+	ItemTypeEpisode ItemType = "episode"
+)
+
+// _episodes caches episode details, because the currently playing endpoint
+// returns episodes without the show.
+var _episodes = expirable.NewLRU[spotify.ID, *spotify.EpisodePage](20, nil, time.Hour)
+
 type CurrentPlaying struct {
+	Type       ItemType
 	ID         string
 	Name       string
 	Artists    string
 	Artist     string
 	ArtistID   string
+	Album      string
 	ProgressMs int
 	DurationMs int
 	Link       string
 	CoverURL   *string
 	Playing    bool
 
+	// Episode only.
+	ShowName    string
+	Publisher   string
+	Description string
+
+	// Where the item is played from. Can be nil.
+	Context *PlaybackContext
+
+	// Playback state. Filled only with PlayingOptions.State.
+	// Device can be nil.
+	Device  *Device
+	Shuffle bool
+	// Repeat is "off", "track" or "context".
+	Repeat string
+
 	FullTrack *spotify.FullTrack
+	Episode   *spotify.EpisodePage
 }
 
-func GetCurrentPlaying(ctx context.Context, cl *spotify.Client) (*CurrentPlaying, error) {
-	curPlay, err := cl.PlayerCurrentlyPlaying(ctx, _market)
+func GetCurrentPlaying(ctx context.Context, cl *spotify.Client, market *Market, opts PlayingOptions) (*CurrentPlaying, error) {
+	reqOpts := []spotify.RequestOption{
+		spotify.Market(market.Code(ctx)),
+		spotify.AdditionalTypes(spotify.EpisodeAdditionalType, spotify.TrackAdditionalType),
+	}
+
+	var (
+		curPlay *spotify.CurrentlyPlaying
+		state   *spotify.PlayerState
+		err     error
+	)
+	if opts.State {
+		state, err = cl.PlayerState(ctx, reqOpts...)
+		if state != nil {
+			curPlay = &state.CurrentlyPlaying
+		}
+	} else {
+		curPlay, err = cl.PlayerCurrentlyPlaying(ctx, reqOpts...)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	playing, err := newCurrentPlaying(ctx, cl, market, curPlay)
+	if err != nil || playing == nil {
+		return playing, err
+	}
+	playing.fillPlayback(ctx, cl, curPlay, state, opts)
+	return playing, nil
+}
+
+func newCurrentPlaying(ctx context.Context, cl *spotify.Client, market *Market, curPlay *spotify.CurrentlyPlaying) (*CurrentPlaying, error) {
 	if curPlay == nil || curPlay.Item == nil || curPlay.Item.ExternalURLs == nil {
 		return nil, nil
 	}
@@ -37,6 +96,10 @@ func GetCurrentPlaying(ctx context.Context, cl *spotify.Client) (*CurrentPlaying
 		return nil, nil
 	}
 
+	if curPlay.Item.Type == string(ItemTypeEpisode) {
+		return getCurrentEpisode(ctx, cl, market, curPlay, spotifyLink)
+	}
+
 	sTrack := curPlay.Item.SimpleTrack
 	if len(sTrack.Artists) == 0 {
 		return nil, nil
@@ -56,11 +119,13 @@ func GetCurrentPlaying(ctx context.Context, cl *spotify.Client) (*CurrentPlaying
 	}
 
 	curPlaying := &CurrentPlaying{
+		Type:       ItemTypeTrack,
 		ID:         sTrack.ID.String(),
 		Name:       sTrack.Name,
 		Artists:    strings.Join(artistsNames, ", "),
 		Artist:     artistName,
 		ArtistID:   artistID,
+		Album:      curPlay.Item.Album.Name,
 		DurationMs: int(sTrack.Duration),
 		ProgressMs: int(curPlay.Progress),
 		Link:       spotifyLink,
@@ -71,3 +136,43 @@ func GetCurrentPlaying(ctx context.Context, cl *spotify.Client) (*CurrentPlaying
 
 	return curPlaying, nil
 }
+
+func getCurrentEpisode(ctx context.Context, cl *spotify.Client, market *Market, curPlay *spotify.CurrentlyPlaying, spotifyLink string) (*CurrentPlaying, error) {
+	id := curPlay.Item.ID
+
+	episode, ok := _episodes.Get(id)
+	if !ok {
+		var err error
+		episode, err = cl.GetEpisode(ctx, id.String(), spotify.Market(market.Code(ctx)))
+		if err != nil {
+			return nil, err
+		}
+		_episodes.Add(id, episode)
+	}
+
+	var coverURL *string
+	if len(episode.Images) > 0 {
+		coverURL = &episode.Images[0].URL
+	} else if len(episode.Show.Images) > 0 {
+		coverURL = &episode.Show.Images[0].URL
+	}
+
+	return &CurrentPlaying{
+		Type:        ItemTypeEpisode,
+		ID:          id.String(),
+		Name:        episode.Name,
+		Artists:     episode.Show.Name,
+		Artist:      episode.Show.Name,
+		ArtistID:    episode.Show.ID.String(),
+		DurationMs:  int(episode.Duration_ms),
+		ProgressMs:  int(curPlay.Progress),
+		Link:        spotifyLink,
+		CoverURL:    coverURL,
+		Playing:     curPlay.Playing,
+		ShowName:    episode.Show.Name,
+		Publisher:   episode.Show.Publisher,
+		Description: episode.Description,
+		FullTrack:   curPlay.Item,
+		Episode:     episode,
+	}, nil
+}
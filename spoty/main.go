@@ -15,39 +15,38 @@ import (
 	"golang.org/x/oauth2"
 )
 
-var (
-	_state  = "abc123"
-	_market = spotify.Market("AU")
-)
-
 // Authorize initiates the Spotify OAuth flow and returns a token.
 func Authorize(ctx context.Context, cfg *config.Spotify, onURL func(string)) (*oauth2.Token, error) {
 	return getTokens(ctx, cfg.RedirectURI, cfg.ClientID, cfg.ClientSecret, onURL)
 }
 
 func getTokens(ctx context.Context, redirectURI, clientID, clientSecret string, onURL func(string)) (*oauth2.Token, error) {
-	auth := getAuthenticator(redirectURI, clientID, clientSecret)
-	clientCh := make(chan *spotify.Client, 1)
+	session, err := newAuthSession(getAuthenticator(redirectURI, clientID, clientSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tokenCh := make(chan *oauth2.Token, 1)
 	errCh := make(chan error, 1)
 
 	// Start temporary HTTP server to handle redirect
 	go func() {
 		if err := serve(ctx, redirectURI, func(w http.ResponseWriter, r *http.Request) {
-			handleOAuthCallback(auth, w, r, clientCh, errCh)
+			handleOAuthCallback(session, w, r, tokenCh, errCh)
 		}); err != nil && !errors.Is(err, context.Canceled) {
 			errCh <- err
 		}
 	}()
 
 	// Provide URL to the user
-	go onURL(auth.AuthURL(_state))
+	go onURL(session.URL())
 
 	select {
-	case client := <-clientCh:
-		if client == nil {
-			return nil, errors.New("received nil Spotify client")
-		}
-		return client.Token()
+	case token := <-tokenCh:
+		return token, nil
 	case err := <-errCh:
 		return nil, err
 	case <-ctx.Done():
@@ -55,23 +54,40 @@ func getTokens(ctx context.Context, redirectURI, clientID, clientSecret string,
 	}
 }
 
-func handleOAuthCallback(auth *spotifyauth.Authenticator, w http.ResponseWriter, r *http.Request, clientCh chan<- *spotify.Client, errCh chan<- error) {
-	token, err := auth.Token(r.Context(), _state, r)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get token: %v", err), http.StatusInternalServerError)
+// handleOAuthCallback completes the session.
+// Callbacks with a foreign state or replays are rejected without aborting the flow,
+// so random requests to a public redirect URI can't break the authorization.
+func handleOAuthCallback(session *authSession, w http.ResponseWriter, r *http.Request, tokenCh chan<- *oauth2.Token, errCh chan<- error) {
+	query := r.URL.Query()
+
+	if err := session.claim(query.Get("state")); err != nil {
+		slog.Warn("rejected Spotify OAuth callback", "err", err, "remote", r.RemoteAddr)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if errMsg := query.Get("error"); errMsg != "" {
+		err := fmt.Errorf("authorization denied: %s", errMsg)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		errCh <- err
 		return
 	}
 
-	if state := r.FormValue("state"); state != _state {
-		msg := fmt.Errorf("state mismatch. Expected %s, got %s", _state, state)
-		http.Error(w, msg.Error(), http.StatusBadRequest)
-		errCh <- msg
+	code := query.Get("code")
+	if code == "" {
+		err := errors.New("no code in callback")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		errCh <- err
 		return
 	}
 
-	client := spotify.New(auth.Client(r.Context(), token), spotify.WithRetry(true))
-	clientCh <- client
+	token, err := session.exchange(r.Context(), code)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get token: %v", err), http.StatusInternalServerError)
+		errCh <- err
+		return
+	}
+	tokenCh <- token
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Authorization successful! You can now return to the application."))
@@ -84,6 +100,7 @@ func getAuthenticator(redirectURI, clientID, clientSecret string) *spotifyauth.A
 		spotifyauth.WithRedirectURL(redirectURI),
 		spotifyauth.WithScopes(
 			spotifyauth.ScopeUserReadCurrentlyPlaying,
+			spotifyauth.ScopeUserReadPlaybackState,
 			spotifyauth.ScopeUserReadPrivate,
 			spotifyauth.ScopeUserLibraryRead,
 			spotifyauth.ScopeUserLibraryModify,
@@ -133,9 +150,12 @@ func serve(ctx context.Context, redirectURI string, handler http.HandlerFunc) er
 	return nil
 }
 
-// GetClient returns a Spotify client from an OAuth token
-func GetClient(redirectURI, clientID, clientSecret string, token *oauth2.Token) *spotify.Client {
+// GetClient returns a Spotify client from an OAuth token.
+// Refreshed tokens are reported through hooks, so they can be persisted.
+// ctx cancels token refreshes, like on shutdown.
+func GetClient(ctx context.Context, redirectURI, clientID, clientSecret string, token *oauth2.Token, hooks TokenHooks) *spotify.Client {
 	auth := getAuthenticator(redirectURI, clientID, clientSecret)
-	httpClient := auth.Client(context.Background(), token)
+	ts := newPersistingTokenSource(ctx, auth, token, hooks)
+	httpClient := oauth2.NewClient(ctx, ts)
 	return spotify.New(httpClient, spotify.WithRetry(true))
 }
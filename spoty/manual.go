@@ -0,0 +1,96 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/locale"
+	"golang.org/x/oauth2"
+)
+
+// AskFunc sends text to the user and returns the user's answer.
+type AskFunc func(ctx context.Context, text string) (string, error)
+
+// AuthorizeManual runs the Spotify OAuth flow without a local HTTP server.
+// The user opens the URL, and pastes the redirect URL back through ask.
+// Invalid answers are reported to the user and asked again until ctx is done.
+func AuthorizeManual(ctx context.Context, cfg *config.Spotify, ask AskFunc) (*oauth2.Token, error) {
+	session, err := newAuthSession(getAuthenticator(cfg.RedirectURI, cfg.ClientID, cfg.ClientSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := locale.T(locale.AuthPrompt, session.URL())
+
+	for {
+		answer, err := ask(ctx, prompt)
+		if err != nil {
+			return nil, err
+		}
+
+		code, err := parseAuthAnswer(answer, session)
+		if err == nil {
+			err = session.claimCode(code)
+		}
+		if err != nil {
+			prompt = locale.T(locale.AuthRetry, authErrorText(err))
+			continue
+		}
+
+		token, err := session.exchange(ctx, code)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			prompt = locale.T(locale.AuthTokenFailed, err.Error())
+			continue
+		}
+		return token, nil
+	}
+}
+
+// parseAuthAnswer extracts the authorization code from a pasted redirect URL.
+// A bare code is not accepted, since the state can't be checked without the URL.
+func parseAuthAnswer(answer string, session *authSession) (string, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return "", errors.New(locale.T(locale.AuthEmptyAnswer))
+	}
+
+	rawQuery := answer
+	if u, err := url.Parse(answer); err == nil && u.RawQuery != "" {
+		rawQuery = u.RawQuery
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", locale.T(locale.AuthInvalidURL), err)
+	}
+
+	if errMsg := query.Get("error"); errMsg != "" {
+		return "", errors.New(locale.T(locale.AuthDenied, errMsg))
+	}
+	if err := session.checkState(query.Get("state")); err != nil {
+		return "", err
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New(locale.T(locale.AuthNoCode))
+	}
+	return code, nil
+}
+
+// authErrorText returns the localized text of the session errors.
+func authErrorText(err error) string {
+	switch {
+	case errors.Is(err, errStateMismatch):
+		return locale.T(locale.AuthStateMismatch)
+	case errors.Is(err, errCodeUsed):
+		return locale.T(locale.AuthCodeUsed)
+	}
+	return err.Error()
+}
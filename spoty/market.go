@@ -0,0 +1,69 @@
+package spoty
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// MarketAuto takes the market from the user's profile country.
+const MarketAuto = "auto"
+
+// Market resolves the market (ISO 3166-1 alpha-2 country code) for API requests.
+// Tracks are relinked for this market, so they are playable in the user's country.
+type Market struct {
+	client *spotify.Client
+	code   string
+
+	mu          sync.Mutex
+	profileCode string
+}
+
+// NewMarket creates a market resolver.
+// code is a country code, or MarketAuto (or empty) to use the profile country.
+func NewMarket(client *spotify.Client, code string) *Market {
+	code = strings.TrimSpace(code)
+	if !strings.EqualFold(code, MarketAuto) {
+		code = strings.ToUpper(code)
+	} else {
+		code = ""
+	}
+	return &Market{
+		client: client,
+		code:   code,
+	}
+}
+
+// Code returns the market code.
+// In auto mode the profile country is fetched once and cached.
+func (m *Market) Code(ctx context.Context) string {
+	if m.code != "" {
+		return m.code
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.profileCode != "" {
+		return m.profileCode
+	}
+
+	user, err := m.client.CurrentUser(ctx)
+	if err != nil {
+		// Let Spotify decide for now, try again next time.
+		slog.Warn("failed to get Spotify user country", "err", err)
+		return spotify.MarketFromToken
+	}
+	if user.Country == "" {
+		// No user-read-private scope.
+		m.profileCode = spotify.MarketFromToken
+	} else {
+		m.profileCode = user.Country
+		slog.Info("Spotify market taken from profile", "market", user.Country)
+	}
+
+	return m.profileCode
+}
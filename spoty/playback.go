@@ -0,0 +1,157 @@
+package spoty
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Context types.
+const (
+	ContextTypeAlbum      = "album"
+	ContextTypeArtist     = "artist"
+	ContextTypePlaylist   = "playlist"
+	ContextTypeShow       = "show"
+	ContextTypeCollection = "collection"
+)
+
+// _contextNames caches context names by URI.
+var _contextNames = expirable.NewLRU[spotify.URI, string](50, nil, 30*time.Minute)
+
+// _contextNameMisses caches failed lookups by URI, like Spotify-owned playlists,
+// which are not found for new apps. Otherwise they are requested on every poll.
+var _contextNameMisses = expirable.NewLRU[spotify.URI, struct{}](50, nil, 10*time.Minute)
+
+// PlayingOptions selects extra data for GetCurrentPlaying.
+type PlayingOptions struct {
+	// State fetches the device, volume, shuffle and repeat state.
+	// Requires the user-read-playback-state scope.
+	State bool
+
+	// ContextName resolves the name of the playing playlist, album or artist.
+	ContextName bool
+}
+
+// PlaybackContext is where the item is played from.
+type PlaybackContext struct {
+	// Type is one of ContextType* constants.
+	Type string
+	// Name may be empty, if it's not requested or can't be resolved.
+	Name string
+	Link string
+}
+
+// Device is the device the item is played on.
+type Device struct {
+	Name string
+	// Type is like "Computer", "Smartphone" or "Speaker".
+	Type          string
+	VolumePercent int
+}
+
+// fillPlayback fills the playback context and state.
+func (c *CurrentPlaying) fillPlayback(ctx context.Context, cl *spotify.Client, curPlay *spotify.CurrentlyPlaying, state *spotify.PlayerState, opts PlayingOptions) {
+	if len(curPlay.PlaybackContext.URI) > 0 {
+		pbCtx := &PlaybackContext{
+			Type: curPlay.PlaybackContext.Type,
+			Link: curPlay.PlaybackContext.ExternalURLs["spotify"],
+		}
+		if opts.ContextName {
+			pbCtx.Name = c.contextName(ctx, cl, curPlay.PlaybackContext)
+		}
+		c.Context = pbCtx
+	}
+
+	if state == nil {
+		return
+	}
+	if len(state.Device.Name) > 0 {
+		c.Device = &Device{
+			Name:          state.Device.Name,
+			Type:          state.Device.Type,
+			VolumePercent: int(state.Device.Volume),
+		}
+	}
+	c.Shuffle = state.ShuffleState
+	c.Repeat = state.RepeatState
+}
+
+// contextName returns the name of the context, trying to avoid requests.
+func (c *CurrentPlaying) contextName(ctx context.Context, cl *spotify.Client, pbCtx spotify.PlaybackContext) string {
+	switch pbCtx.Type {
+	case ContextTypeCollection:
+		return "Liked Songs"
+	case ContextTypeShow:
+		if len(c.ShowName) > 0 {
+			return c.ShowName
+		}
+	case ContextTypeAlbum:
+		if c.FullTrack != nil && c.FullTrack.Album.URI == pbCtx.URI {
+			return c.FullTrack.Album.Name
+		}
+	case ContextTypeArtist:
+		if c.FullTrack != nil {
+			for _, ar := range c.FullTrack.Artists {
+				if ar.URI == pbCtx.URI {
+					return ar.Name
+				}
+			}
+		}
+	}
+
+	if name, ok := _contextNames.Get(pbCtx.URI); ok {
+		return name
+	}
+	if _contextNameMisses.Contains(pbCtx.URI) {
+		return ""
+	}
+
+	name, err := fetchContextName(ctx, cl, pbCtx)
+	if err != nil {
+		slog.Debug("failed to get Spotify context name", "uri", pbCtx.URI, "err", err)
+		if ctx.Err() == nil {
+			_contextNameMisses.Add(pbCtx.URI, struct{}{})
+		}
+		return ""
+	}
+	_contextNames.Add(pbCtx.URI, name)
+	return name
+}
+
+func fetchContextName(ctx context.Context, cl *spotify.Client, pbCtx spotify.PlaybackContext) (string, error) {
+	// spotify:playlist:ID
+	parts := strings.Split(string(pbCtx.URI), ":")
+	id := spotify.ID(parts[len(parts)-1])
+
+	switch pbCtx.Type {
+	case ContextTypePlaylist:
+		playlist, err := cl.GetPlaylist(ctx, id, spotify.Fields("name"))
+		if err != nil {
+			return "", err
+		}
+		return playlist.Name, nil
+	case ContextTypeAlbum:
+		album, err := cl.GetAlbum(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return album.Name, nil
+	case ContextTypeArtist:
+		artist, err := cl.GetArtist(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return artist.Name, nil
+	case ContextTypeShow:
+		show, err := cl.GetShow(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		return show.Name, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,31 @@
+package spoty
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+func TestContextNameMiss(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"status":404,"message":"Resource not found"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	cl := spotify.New(srv.Client(), spotify.WithBaseURL(srv.URL+"/"))
+
+	pbCtx := spotify.PlaybackContext{Type: ContextTypePlaylist, URI: "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M"}
+	for range 3 {
+		if name := (&CurrentPlaying{}).contextName(context.Background(), cl, pbCtx); name != "" {
+			t.Fatalf("unexpected name %q", name)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 request, got %d", calls)
+	}
+}
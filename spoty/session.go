@@ -0,0 +1,100 @@
+package spoty
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	errStateMismatch = errors.New("state mismatch")
+	errSessionUsed   = errors.New("authorization callback already used")
+	errCodeUsed      = errors.New("authorization code already used")
+)
+
+// authSession is a single authorization attempt.
+// Every attempt has its own random state and PKCE verifier,
+// and can be completed only once.
+type authSession struct {
+	auth     *spotifyauth.Authenticator
+	state    string
+	verifier string
+	used     atomic.Bool
+
+	// codes are the exchanged codes of the manual flow,
+	// which retries the session with new codes after failures.
+	codesMu sync.Mutex
+	codes   map[string]struct{}
+}
+
+func newAuthSession(auth *spotifyauth.Authenticator) (*authSession, error) {
+	state, err := randomState()
+	if err != nil {
+		return nil, fmt.Errorf("generate state: %w", err)
+	}
+	return &authSession{
+		auth:     auth,
+		state:    state,
+		verifier: oauth2.GenerateVerifier(),
+	}, nil
+}
+
+// URL returns the authorization URL for this session.
+func (s *authSession) URL() string {
+	return s.auth.AuthURL(s.state, oauth2.S256ChallengeOption(s.verifier))
+}
+
+// checkState reports whether state belongs to this session.
+func (s *authSession) checkState(state string) error {
+	if subtle.ConstantTimeCompare([]byte(state), []byte(s.state)) != 1 {
+		return errStateMismatch
+	}
+	return nil
+}
+
+// claim marks the session as used. Only the first caller with a valid state succeeds.
+func (s *authSession) claim(state string) error {
+	if err := s.checkState(state); err != nil {
+		return err
+	}
+	if !s.used.CompareAndSwap(false, true) {
+		return errSessionUsed
+	}
+	return nil
+}
+
+// claimCode marks the code as exchanged. Every code is accepted only once,
+// so a replayed answer of the manual flow is rejected.
+func (s *authSession) claimCode(code string) error {
+	s.codesMu.Lock()
+	defer s.codesMu.Unlock()
+	if _, ok := s.codes[code]; ok {
+		return errCodeUsed
+	}
+	if s.codes == nil {
+		s.codes = make(map[string]struct{})
+	}
+	s.codes[code] = struct{}{}
+	return nil
+}
+
+// exchange trades the authorization code for a token.
+func (s *authSession) exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	return s.auth.Exchange(ctx, code, oauth2.VerifierOption(s.verifier))
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,82 @@
+package spoty
+
+import (
+	"errors"
+	"testing"
+)
+
+func getSession(t *testing.T) *authSession {
+	session, err := newAuthSession(getAuthenticator("http://127.0.0.1:3000/spotify", "id", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func TestAuthSessionRandomState(t *testing.T) {
+	a, b := getSession(t), getSession(t)
+	if a.state == b.state {
+		t.Fatal("sessions share the same state")
+	}
+	if a.verifier == b.verifier {
+		t.Fatal("sessions share the same verifier")
+	}
+}
+
+func TestAuthSessionClaim(t *testing.T) {
+	session := getSession(t)
+
+	if err := session.claim("foreign"); !errors.Is(err, errStateMismatch) {
+		t.Fatalf("expected state mismatch, got %v", err)
+	}
+	if err := session.claim(session.state); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.claim(session.state); !errors.Is(err, errSessionUsed) {
+		t.Fatalf("expected replay rejection, got %v", err)
+	}
+}
+
+func TestAuthSessionClaimCode(t *testing.T) {
+	session := getSession(t)
+
+	if err := session.claimCode("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.claimCode("def"); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.claimCode("abc"); !errors.Is(err, errCodeUsed) {
+		t.Fatalf("expected replay rejection, got %v", err)
+	}
+}
+
+func TestParseAuthAnswer(t *testing.T) {
+	session := getSession(t)
+
+	tests := []struct {
+		name    string
+		answer  string
+		code    string
+		wantErr bool
+	}{
+		{name: "bare code", answer: " abc ", wantErr: true},
+		{name: "redirect URL", answer: "http://127.0.0.1:3000/spotify?code=abc&state=" + session.state, code: "abc"},
+		{name: "query only", answer: "code=abc&state=" + session.state, code: "abc"},
+		{name: "foreign state", answer: "http://127.0.0.1:3000/spotify?code=abc&state=foreign", wantErr: true},
+		{name: "denied", answer: "http://127.0.0.1:3000/spotify?error=access_denied&state=" + session.state, wantErr: true},
+		{name: "empty", answer: "  ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := parseAuthAnswer(tt.answer, session)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code != tt.code {
+				t.Fatalf("expected code %q, got %q", tt.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package spoty
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
+)
+
+// TokenHooks are called by the client token source.
+type TokenHooks struct {
+	// OnToken is called when Spotify issued a new access or refresh token.
+	OnToken func(token *oauth2.Token) error
+
+	// OnRefreshFailed is called once when Spotify rejected the refresh token.
+	// It's called again only after a successful refresh.
+	OnRefreshFailed func(err error)
+}
+
+// persistingTokenSource refreshes the token and reports every change to hooks.
+type persistingTokenSource struct {
+	// ctx cancels the refresh, like on shutdown.
+	ctx   context.Context
+	auth  *spotifyauth.Authenticator
+	hooks TokenHooks
+
+	mu     sync.Mutex
+	token  *oauth2.Token
+	failed bool
+}
+
+func newPersistingTokenSource(ctx context.Context, auth *spotifyauth.Authenticator, token *oauth2.Token, hooks TokenHooks) *persistingTokenSource {
+	return &persistingTokenSource{
+		ctx:   ctx,
+		auth:  auth,
+		hooks: hooks,
+		token: token,
+	}
+}
+
+// Token implements oauth2.TokenSource.
+// Hooks are called after the lock is released, so they don't block other requests.
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, changed, rejected, err := s.refresh()
+	if err != nil {
+		if rejected && s.hooks.OnRefreshFailed != nil {
+			s.hooks.OnRefreshFailed(err)
+		}
+		return nil, err
+	}
+
+	if changed && s.hooks.OnToken != nil {
+		if err := s.hooks.OnToken(token); err != nil {
+			slog.Error("failed to persist refreshed Spotify token", "err", err)
+		}
+	}
+
+	return token, nil
+}
+
+// refresh refreshes the token if it's expired. changed is true if the token was changed,
+// rejected is true the first time Spotify rejected the refresh token.
+func (s *persistingTokenSource) refresh() (token *oauth2.Token, changed, rejected bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token, false, false, nil
+	}
+
+	token, err = s.auth.RefreshToken(s.ctx, s.token)
+	if err != nil {
+		if isRefreshRejected(err) && !s.failed {
+			// Refresh token revoked or expired. Retrying won't help.
+			s.failed = true
+			rejected = true
+		}
+		return nil, false, rejected, err
+	}
+	s.failed = false
+
+	// Spotify may omit the refresh token if it was not rotated.
+	if token.RefreshToken == "" {
+		token.RefreshToken = s.token.RefreshToken
+	}
+
+	changed = token.AccessToken != s.token.AccessToken || token.RefreshToken != s.token.RefreshToken
+	s.token = token
+	return token, changed, false, nil
+}
+
+// isRefreshRejected reports whether Spotify rejected the refresh token itself.
+// Server errors and rate limits are transient, so the next tick retries.
+func isRefreshRejected(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		return false
+	}
+	if retrieveErr.ErrorCode == "invalid_grant" {
+		return true
+	}
+	if retrieveErr.Response == nil {
+		return false
+	}
+	code := retrieveErr.Response.StatusCode
+	return code >= 400 && code < 500 && code != http.StatusTooManyRequests
+}
@@ -0,0 +1,41 @@
+package spoty
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestIsRefreshRejected(t *testing.T) {
+	retrieveErr := func(status int, code string) error {
+		return fmt.Errorf("refresh: %w", &oauth2.RetrieveError{
+			Response:  &http.Response{StatusCode: status},
+			ErrorCode: code,
+		})
+	}
+
+	rejected := []error{
+		retrieveErr(http.StatusBadRequest, "invalid_grant"),
+		retrieveErr(http.StatusUnauthorized, "invalid_client"),
+	}
+	for _, err := range rejected {
+		if !isRefreshRejected(err) {
+			t.Fatalf("expected rejected: %v", err)
+		}
+	}
+
+	transient := []error{
+		retrieveErr(http.StatusInternalServerError, ""),
+		retrieveErr(http.StatusServiceUnavailable, ""),
+		retrieveErr(http.StatusTooManyRequests, ""),
+		errors.New("connection reset"),
+	}
+	for _, err := range transient {
+		if isRefreshRejected(err) {
+			t.Fatalf("expected transient: %v", err)
+		}
+	}
+}
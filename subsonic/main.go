@@ -0,0 +1,145 @@
+package subsonic
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	_apiVersion = "1.16.1"
+	_clientName = "teletrack"
+)
+
+// Client is a Subsonic API client. Works with Navidrome, Airsonic, Gonic
+// and other Subsonic-compatible servers.
+type Client struct {
+	// BaseURL is the server URL, like "https://music.example.com".
+	BaseURL  string
+	Username string
+	Password string
+	HTTP     *http.Client
+}
+
+// NewClient creates a new Subsonic API client.
+func NewClient(baseURL, username, password string) *Client {
+	return &Client{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Username: username,
+		Password: password,
+		HTTP:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ApiError represents an error returned by the Subsonic API.
+type ApiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface for ApiError.
+func (e ApiError) Error() string {
+	return fmt.Sprintf("%s, code: %d", e.Message, e.Code)
+}
+
+// envelope is the common part of every JSON response.
+type envelope struct {
+	Response struct {
+		Status string    `json:"status"`
+		Error  *ApiError `json:"error"`
+	} `json:"subsonic-response"`
+}
+
+// endpointURL builds an authenticated URL of the endpoint.
+// Authentication uses token and salt, so the password is never sent.
+func (c *Client) endpointURL(endpoint string, params url.Values) (string, error) {
+	if c.BaseURL == "" {
+		return "", errors.New("base URL is required")
+	}
+	if c.Username == "" {
+		return "", errors.New("username is required")
+	}
+
+	salt, err := randomSalt()
+	if err != nil {
+		return "", err
+	}
+	token := md5.Sum([]byte(c.Password + salt))
+
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("u", c.Username)
+	query.Set("t", hex.EncodeToString(token[:]))
+	query.Set("s", salt)
+	query.Set("v", _apiVersion)
+	query.Set("c", _clientName)
+	query.Set("f", "json")
+
+	return c.BaseURL + "/rest/" + endpoint + "?" + query.Encode(), nil
+}
+
+// get calls the endpoint and decodes the response into result.
+func get[T any](ctx context.Context, c *Client, endpoint string, params url.Values) (*T, error) {
+	apiURL, err := c.endpointURL(endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, err
+	}
+	if env.Response.Error != nil {
+		return nil, *env.Response.Error
+	}
+	if env.Response.Status != "ok" {
+		return nil, fmt.Errorf("unexpected status: %q", env.Response.Status)
+	}
+
+	var result struct {
+		Response T `json:"subsonic-response"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return &result.Response, nil
+}
+
+func randomSalt() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,60 @@
+package subsonic
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func getClient(t *testing.T, handler http.HandlerFunc) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL+"/", "user", "secret")
+}
+
+func TestGetNowPlaying(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/rest/getNowPlaying" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if query.Has("p") {
+			t.Error("password sent in plain text")
+		}
+		token := md5.Sum([]byte("secret" + query.Get("s")))
+		if query.Get("t") != hex.EncodeToString(token[:]) || query.Get("u") != "user" {
+			w.Write([]byte(`{"subsonic-response":{"status":"failed","error":{"code":40,"message":"Wrong username or password"}}}`))
+			return
+		}
+		w.Write([]byte(`{"subsonic-response":{"status":"ok","version":"1.16.1","nowPlaying":{"entry":[
+			{"id":"1","title":"Song","artist":"Artist","artistId":"a1","coverArt":"al-1","duration":200,"username":"user","minutesAgo":0,"playerId":1,"playerName":"Feishin"}
+		]}}}`))
+	})
+
+	entries, err := cl.GetNowPlaying(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Title != "Song" || entries[0].Duration != 200 || entries[0].PlayerName != "Feishin" {
+		t.Fatalf("unexpected entry %+v", entries[0])
+	}
+}
+
+func TestApiError(t *testing.T) {
+	cl := getClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"subsonic-response":{"status":"failed","error":{"code":50,"message":"Sharing is disabled"}}}`))
+	})
+
+	_, err := cl.CreateShare(context.Background(), "1", "")
+	var apiErr ApiError
+	if !errors.As(err, &apiErr) || apiErr.Code != 50 {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
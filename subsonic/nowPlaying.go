@@ -0,0 +1,77 @@
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// NowPlayingEntry is a song played by some user now.
+type NowPlayingEntry struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	ArtistID string `json:"artistId"`
+	Album    string `json:"album"`
+	CoverArt string `json:"coverArt"`
+	// Duration in seconds.
+	Duration   int    `json:"duration"`
+	Username   string `json:"username"`
+	MinutesAgo int    `json:"minutesAgo"`
+	PlayerID   int    `json:"playerId"`
+	PlayerName string `json:"playerName"`
+}
+
+// Share is a public link to songs.
+type Share struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// GetNowPlaying returns what all users are playing now.
+func (c *Client) GetNowPlaying(ctx context.Context) ([]NowPlayingEntry, error) {
+	resp, err := get[struct {
+		NowPlaying struct {
+			Entry []NowPlayingEntry `json:"entry"`
+		} `json:"nowPlaying"`
+	}](ctx, c, "getNowPlaying", nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.NowPlaying.Entry, nil
+}
+
+// CreateShare creates a public link to the song (or album, etc).
+// Sharing must be enabled on the server.
+func (c *Client) CreateShare(ctx context.Context, id, description string) (*Share, error) {
+	params := url.Values{}
+	params.Set("id", id)
+	if description != "" {
+		params.Set("description", description)
+	}
+
+	resp, err := get[struct {
+		Shares struct {
+			Share []Share `json:"share"`
+		} `json:"shares"`
+	}](ctx, c, "createShare", params)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Shares.Share) == 0 {
+		return nil, errors.New("server returned no share")
+	}
+	return &resp.Shares.Share[0], nil
+}
+
+// CoverArtURL returns an authenticated URL of the cover image.
+// The URL contains an authentication token, so whoever gets it can call the API as the user.
+func (c *Client) CoverArtURL(coverArtID string, size int) (string, error) {
+	params := url.Values{}
+	params.Set("id", coverArtID)
+	if size > 0 {
+		params.Set("size", strconv.Itoa(size))
+	}
+	return c.endpointURL("getCoverArt", params)
+}
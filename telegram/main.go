@@ -2,23 +2,50 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/oklookat/teletrack/config"
+	"github.com/oklookat/teletrack/locale"
 )
 
-type Module interface {
-	Handle(ctx context.Context, b *bot.Bot)
+// Command is a bot command, available to the owner only.
+type Command struct {
+	// Name without slash, like "now".
+	Name        string
+	Description string
+	// Handler returns the reply text.
+	Handler func(ctx context.Context, args string) string
+}
+
+// Commander is a module with commands.
+type Commander interface {
+	Commands() []Command
 }
 
 type TelegramBot struct {
-	cfg    *config.Telegram
-	bot    *bot.Bot
-	ready  bool
-	stopCh chan struct{}
+	cfg      *config.Telegram
+	bot      *bot.Bot
+	ready    bool
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	// answerCh receives the next owner message while Ask is waiting.
+	answerMu sync.Mutex
+	answerCh chan string
+
+	commandsMu sync.RWMutex
+	commands   []Command
+
+	// ctx is the context of the modules.
+	ctx       context.Context
+	modulesMu sync.Mutex
+	modules   []Module
 }
 
 // NewTelegramBot initializes and starts the bot
@@ -27,6 +54,15 @@ func NewTelegramBot(ctx context.Context, tgCfg *config.Telegram, modules []Modul
 		cfg:    tgCfg,
 		ready:  tgCfg.UserID > 0 && len(tgCfg.ServiceChatID) > 0,
 		stopCh: make(chan struct{}),
+		ctx:    ctx,
+	}
+	tg.commands = []Command{
+		{Name: "help", Description: locale.T(locale.CmdHelp), Handler: tg.handleHelp},
+		{Name: "stop", Description: locale.T(locale.CmdStop)},
+		{Name: "modules", Description: locale.T(locale.CmdModules), Handler: tg.handleModules},
+		{Name: "startmodule", Description: locale.T(locale.CmdStartModule), Handler: tg.handleStartModule},
+		{Name: "stopmodule", Description: locale.T(locale.CmdStopModule), Handler: tg.handleStopModule},
+		{Name: "restart", Description: locale.T(locale.CmdRestart), Handler: tg.handleRestart},
 	}
 
 	// Initialize bot with default handler
@@ -48,12 +84,143 @@ func NewTelegramBot(ctx context.Context, tgCfg *config.Telegram, modules []Modul
 
 	// Attach modules
 	for _, m := range modules {
-		m.Handle(ctx, b)
+		if err := tg.AddModule(m); err != nil {
+			return nil, err
+		}
 	}
 
 	return tg, nil
 }
 
+// AddModule starts a module on the running bot, and registers its commands.
+// The module runs in the context of the bot, like after a restart.
+func (tg *TelegramBot) AddModule(m Module) error {
+	if err := m.Start(tg.ctx, tg.bot); err != nil {
+		return fmt.Errorf("start module %s: %w", m.Name(), err)
+	}
+	tg.modulesMu.Lock()
+	tg.modules = append(tg.modules, m)
+	tg.modulesMu.Unlock()
+
+	if commander, ok := m.(Commander); ok {
+		tg.AddCommands(tg.ctx, commander.Commands()...)
+	}
+	return nil
+}
+
+// AddCommands registers commands, and updates the command list of the owner chat.
+func (tg *TelegramBot) AddCommands(ctx context.Context, commands ...Command) {
+	tg.commandsMu.Lock()
+	tg.commands = append(tg.commands, commands...)
+	botCommands := make([]models.BotCommand, 0, len(tg.commands))
+	for _, cmd := range tg.commands {
+		botCommands = append(botCommands, models.BotCommand{Command: cmd.Name, Description: cmd.Description})
+	}
+	tg.commandsMu.Unlock()
+
+	if !tg.ready {
+		return
+	}
+	if _, err := tg.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+		Commands: botCommands,
+		Scope:    &models.BotCommandScopeChat{ChatID: tg.cfg.UserID},
+	}); err != nil {
+		slog.Error("failed to set commands", "err", err)
+	}
+}
+
+// command finds the command of the message, like "/now@bot args".
+func (tg *TelegramBot) command(text string) (*Command, string, bool) {
+	if !strings.HasPrefix(text, "/") {
+		return nil, "", false
+	}
+	name, args, _ := strings.Cut(strings.TrimPrefix(text, "/"), " ")
+	name, _, _ = strings.Cut(name, "@")
+
+	tg.commandsMu.RLock()
+	defer tg.commandsMu.RUnlock()
+	for i := range tg.commands {
+		if tg.commands[i].Name == name {
+			cmd := tg.commands[i]
+			return &cmd, strings.TrimSpace(args), true
+		}
+	}
+	return nil, "", true
+}
+
+func (tg *TelegramBot) handleHelp(ctx context.Context, args string) string {
+	tg.commandsMu.RLock()
+	defer tg.commandsMu.RUnlock()
+	lines := make([]string, 0, len(tg.commands))
+	for _, cmd := range tg.commands {
+		lines = append(lines, fmt.Sprintf("/%s - %s", cmd.Name, cmd.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Ask sends text to the owner and waits for the owner's next message.
+func (tg *TelegramBot) Ask(ctx context.Context, text string) (string, error) {
+	if !tg.ready {
+		return "", errors.New(locale.T(locale.BotNotReady))
+	}
+
+	answerCh := make(chan string, 1)
+	tg.answerMu.Lock()
+	if tg.answerCh != nil {
+		tg.answerMu.Unlock()
+		return "", errors.New(locale.T(locale.BotAlreadyAsking))
+	}
+	tg.answerCh = answerCh
+	tg.answerMu.Unlock()
+
+	defer func() {
+		tg.answerMu.Lock()
+		tg.answerCh = nil
+		tg.answerMu.Unlock()
+	}()
+
+	if _, err := tg.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: tg.cfg.UserID,
+		Text:   text,
+	}); err != nil {
+		return "", fmt.Errorf("failed to send question: %w", err)
+	}
+
+	select {
+	case answer := <-answerCh:
+		return answer, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Notify sends text to the owner.
+func (tg *TelegramBot) Notify(ctx context.Context, text string) {
+	if !tg.ready {
+		return
+	}
+	if _, err := tg.bot.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: tg.cfg.UserID,
+		Text:   text,
+	}); err != nil {
+		slog.Error("failed to send notification", "err", err)
+	}
+}
+
+// deliverAnswer passes the message to Ask, if it's waiting.
+func (tg *TelegramBot) deliverAnswer(text string) bool {
+	tg.answerMu.Lock()
+	defer tg.answerMu.Unlock()
+	if tg.answerCh == nil {
+		return false
+	}
+	select {
+	case tg.answerCh <- text:
+	default:
+	}
+	return true
+}
+
 // handleInit is the default handler for the bot
 func (tg *TelegramBot) handleInit(ctx context.Context, b *bot.Bot, update *models.Update) {
 	chatID := getChatIDByUpdate(update)
@@ -67,7 +234,7 @@ func (tg *TelegramBot) handleInit(ctx context.Context, b *bot.Bot, update *model
 		if chatID != nil {
 			_, err := b.SendMessage(ctx, &bot.SendMessageParams{
 				ChatID: *chatID,
-				Text:   fmt.Sprintf("Telegram user ID: %d\nService chat ID: %d", update.Message.From.ID, *chatID),
+				Text:   locale.T(locale.BotInit, update.Message.From.ID, *chatID),
 			})
 			if err != nil {
 				slog.Error("failed to send init message", "err", err)
@@ -80,10 +247,22 @@ func (tg *TelegramBot) handleInit(ctx context.Context, b *bot.Bot, update *model
 		return
 	}
 
-	respMsg := "All good."
-	isStop := update.Message.Text == "/stop"
-	if isStop {
-		respMsg = "Bot stopped."
+	text := update.Message.Text
+	cmd, args, isCommand := tg.command(text)
+	isStop := cmd != nil && cmd.Name == "stop"
+	// Commands work while Ask is waiting, only plain text is the answer.
+	if !isCommand && tg.deliverAnswer(text) {
+		return
+	}
+
+	respMsg := locale.T(locale.BotAllGood)
+	switch {
+	case isStop:
+		respMsg = locale.T(locale.BotStopped)
+	case cmd != nil && cmd.Handler != nil:
+		respMsg = cmd.Handler(ctx, args)
+	case isCommand:
+		respMsg = locale.T(locale.BotUnknownCommand)
 	}
 
 	if chatID != nil {
@@ -96,7 +275,8 @@ func (tg *TelegramBot) handleInit(ctx context.Context, b *bot.Bot, update *model
 	}
 
 	if isStop {
-		close(tg.stopCh)
+		// Repeated /stop while shutting down.
+		tg.stopOnce.Do(func() { close(tg.stopCh) })
 	}
 }
 
@@ -109,7 +289,7 @@ func (tg *TelegramBot) SendError(ctx context.Context, err error) {
 
 	if _, sendErr := tg.bot.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: tg.cfg.ServiceChatID,
-		Text:   fmt.Sprintf("Error: %s", err.Error()),
+		Text:   locale.T(locale.BotError, err.Error()),
 	}); sendErr != nil {
 		slog.Error("failed to send error message", "err", sendErr)
 	}
@@ -0,0 +1,148 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/oklookat/teletrack/locale"
+)
+
+// ModuleState is the lifecycle state of a module.
+type ModuleState string
+
+const (
+	ModuleRunning ModuleState = "running"
+	ModuleStopped ModuleState = "stopped"
+)
+
+// ErrModuleRunning is returned by Module.Start, if the module is already running.
+var ErrModuleRunning = errors.New("module is already running")
+
+// Module is a part of the bot, which can be stopped and started again.
+type Module interface {
+	// Name is the short name, used in commands.
+	Name() string
+	// Start starts the module in background. The module runs until Stop, or ctx is done.
+	Start(ctx context.Context, b *bot.Bot) error
+	// Stop stops the module, and waits until it's stopped.
+	Stop()
+	Status() ModuleState
+}
+
+// StartModule starts the stopped module.
+func (tg *TelegramBot) StartModule(name string) error {
+	m, err := tg.module(name)
+	if err != nil {
+		return err
+	}
+	return m.Start(tg.ctx, tg.bot)
+}
+
+// StopModule stops the module.
+func (tg *TelegramBot) StopModule(name string) error {
+	m, err := tg.module(name)
+	if err != nil {
+		return err
+	}
+	m.Stop()
+	return nil
+}
+
+// RestartModule stops and starts the module. Empty name restarts all modules.
+func (tg *TelegramBot) RestartModule(name string) error {
+	var modules []Module
+	if name == "" {
+		modules = tg.moduleList()
+	} else {
+		m, err := tg.module(name)
+		if err != nil {
+			return err
+		}
+		modules = []Module{m}
+	}
+
+	var errs []error
+	for _, m := range modules {
+		m.Stop()
+		if err := m.Start(tg.ctx, tg.bot); err != nil {
+			errs = append(errs, fmt.Errorf("start module %s: %w", m.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown stops all modules, and waits until they are stopped.
+func (tg *TelegramBot) Shutdown() {
+	for _, m := range tg.moduleList() {
+		m.Stop()
+		slog.Info("module stopped", "module", m.Name())
+	}
+}
+
+func (tg *TelegramBot) module(name string) (Module, error) {
+	for _, m := range tg.moduleList() {
+		if m.Name() == name {
+			return m, nil
+		}
+	}
+	return nil, errors.New(locale.T(locale.ModuleNotFound, name))
+}
+
+func (tg *TelegramBot) moduleList() []Module {
+	tg.modulesMu.Lock()
+	defer tg.modulesMu.Unlock()
+	return append([]Module(nil), tg.modules...)
+}
+
+func (tg *TelegramBot) handleModules(ctx context.Context, args string) string {
+	modules := tg.moduleList()
+	if len(modules) == 0 {
+		return locale.T(locale.ModuleNone)
+	}
+	lines := make([]string, 0, len(modules))
+	for _, m := range modules {
+		state := locale.T(locale.ModuleStateStopped)
+		if m.Status() == ModuleRunning {
+			state = locale.T(locale.ModuleStateRunning)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", m.Name(), state))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (tg *TelegramBot) handleStartModule(ctx context.Context, args string) string {
+	if args == "" {
+		return locale.T(locale.ModuleUsage, "startmodule")
+	}
+	if err := tg.StartModule(args); err != nil {
+		if errors.Is(err, ErrModuleRunning) {
+			return locale.T(locale.ModuleRunningMsg, args)
+		}
+		return err.Error()
+	}
+	return locale.T(locale.ModuleStarted, args)
+}
+
+func (tg *TelegramBot) handleStopModule(ctx context.Context, args string) string {
+	if args == "" {
+		return locale.T(locale.ModuleUsage, "stopmodule")
+	}
+	if err := tg.StopModule(args); err != nil {
+		return err.Error()
+	}
+	return locale.T(locale.ModuleStoppedMsg, args)
+}
+
+func (tg *TelegramBot) handleRestart(ctx context.Context, args string) string {
+	if err := tg.RestartModule(args); err != nil {
+		return err.Error()
+	}
+	if args == "" {
+		return locale.T(locale.ModuleRestartedAll)
+	}
+	return locale.T(locale.ModuleRestarted, args)
+}